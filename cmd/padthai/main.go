@@ -1,49 +1,414 @@
 package main
 
 import (
+	"bufio"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/lynxnot/base-padthai/pkg/padthai"
 )
 
 func main() {
-	decode := flag.Bool("d", false, "decode mode: read Thai-encoded UTF-8 from stdin and write binary to stdout")
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [-d]\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Encode binary data to Thai Unicode characters, or decode back.\n")
-		fmt.Fprintf(os.Stderr, "Reads from stdin, writes to stdout.\n\n")
-		flag.PrintDefaults()
-	}
-	flag.Parse()
-
-	if *decode {
-		input, err := io.ReadAll(os.Stdin)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "padthai: read error: %v\n", err)
-			os.Exit(1)
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// env holds the standard streams of an invocation.
+type env struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+// command is a subcommand: padthai name [flags].
+type command struct {
+	name    string
+	summary string
+	run     func(e env, args []string) int
+}
+
+// commands lists the subcommands in the order help shows them.
+var commands = []command{
+	{"encode", "encode binary data from stdin or a file to padthai", runEncode},
+	{"decode", "decode padthai from stdin to binary data", runDecode},
+	{"verify", "check that stdin is valid padthai, without writing the data", runVerify},
+	{"info", "describe an encoding, including the fonts needed to display it", runInfo},
+	{"rewrap", "rewrite padthai from stdin at a fixed width, without decoding", runRewrap},
+}
+
+// run executes the command with the given arguments and standard streams,
+// and returns the process exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	e := env{stdin, stdout, stderr}
+	if len(args) > 0 {
+		for _, c := range commands {
+			if args[0] == c.name {
+				return c.run(e, args[1:])
+			}
 		}
-		decoded, err := padthai.Decode(string(input))
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "padthai: decode error: %v\n", err)
-			os.Exit(1)
+	}
+	return runFlags(e, args)
+}
+
+// runFlags executes the command line of earlier releases, which selected the
+// mode with flags rather than a subcommand. Without any, it encodes.
+func runFlags(e env, args []string) int {
+	flags := flag.NewFlagSet("padthai", flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	decode := flags.Bool("d", false, "deprecated: use padthai decode")
+	rewrap := flags.Int("rewrap", 0, "deprecated: use padthai rewrap -w `width`")
+	name := encodingFlag(flags)
+	hexMode := hexFlag(flags)
+	info := flags.Bool("info", false, "deprecated: use padthai info")
+	flags.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: %s <command> [flags]\n\n", flags.Name())
+		fmt.Fprintf(e.stderr, "Encode binary data to Thai Unicode characters, or decode back.\n")
+		fmt.Fprintf(e.stderr, "Reads from stdin, writes to stdout.\n\n")
+		fmt.Fprintf(e.stderr, "Commands:\n")
+		for _, c := range commands {
+			fmt.Fprintf(e.stderr, "  %-8s %s\n", c.name, c.summary)
 		}
-		if _, err := os.Stdout.Write(decoded); err != nil {
-			fmt.Fprintf(os.Stderr, "padthai: write error: %v\n", err)
-			os.Exit(1)
+		fmt.Fprintf(e.stderr, "\nRun '%s <command> -h' for the flags of a command.\n", flags.Name())
+		fmt.Fprintf(e.stderr, "Without a command, %s encodes, accepting these flags:\n\n", flags.Name())
+		flags.PrintDefaults()
+	}
+	if !e.parse(flags, args) {
+		return 2
+	}
+
+	if *decode && *rewrap != 0 {
+		fmt.Fprintf(e.stderr, "padthai: -d and -rewrap cannot be combined\n")
+		return 2
+	}
+	if *hexMode && *rewrap != 0 {
+		fmt.Fprintf(e.stderr, "padthai: -hex and -rewrap cannot be combined\n")
+		return 2
+	}
+	if *rewrap < 0 {
+		fmt.Fprintf(e.stderr, "padthai: invalid rewrap width %d\n", *rewrap)
+		return 2
+	}
+	enc, ok := e.encoding(*name)
+	if !ok {
+		return 2
+	}
+
+	switch {
+	case *info:
+		fmt.Fprintf(e.stderr, "padthai: -info is deprecated; use padthai info\n")
+		return e.info(enc, *name)
+	case *decode:
+		fmt.Fprintf(e.stderr, "padthai: -d is deprecated; use padthai decode\n")
+		return e.decode(enc, *hexMode)
+	case *rewrap > 0:
+		fmt.Fprintf(e.stderr, "padthai: -rewrap is deprecated; use padthai rewrap -w %d\n", *rewrap)
+		return e.rewrap(enc, *rewrap)
+	default:
+		return e.encode(enc, *hexMode)
+	}
+}
+
+func runEncode(e env, args []string) int {
+	flags := e.flagSet("encode", "[-e encoding] [-hex] [file]", "Encode binary data from file, or stdin, to padthai on stdout.")
+	name := encodingFlag(flags)
+	hexMode := hexFlag(flags)
+	if !e.parseArgs(flags, args, 1) {
+		return 2
+	}
+	enc, ok := e.encoding(*name)
+	if !ok {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		return e.encode(enc, *hexMode)
+	}
+
+	path := flags.Arg(0)
+	if !*hexMode && enc == padthai.StdEncoding {
+		// Large files are encoded straight from a memory mapping, which the
+		// streaming encoder only supports for the standard encoding
+		if err := padthai.EncodeMmap(path, e.stdout); err != nil {
+			fmt.Fprintf(e.stderr, "padthai: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "padthai: %v\n", err)
+		return 1
+	}
+	defer f.Close()
+	e.stdin = f
+	return e.encode(enc, *hexMode)
+}
+
+func runDecode(e env, args []string) int {
+	flags := e.flagSet("decode", "[-e encoding] [-hex]", "Decode padthai from stdin to binary data on stdout.")
+	name := encodingFlag(flags)
+	hexMode := hexFlag(flags)
+	if !e.parse(flags, args) {
+		return 2
+	}
+	enc, ok := e.encoding(*name)
+	if !ok {
+		return 2
+	}
+	return e.decode(enc, *hexMode)
+}
+
+func runVerify(e env, args []string) int {
+	flags := e.flagSet("verify", "[-e encoding]", "Check that stdin is valid padthai, and report the size of the data it holds.\nExits with status 1 if it is not valid.")
+	name := encodingFlag(flags)
+	if !e.parse(flags, args) {
+		return 2
+	}
+	enc, ok := e.encoding(*name)
+	if !ok {
+		return 2
+	}
+	decoded, err := enc.DecodeReaderAll(e.stdin)
+	if err != nil {
+		e.inputError(err)
+		return 1
+	}
+	fmt.Fprintf(e.stdout, "valid: %d bytes\n", len(decoded))
+	return 0
+}
+
+func runInfo(e env, args []string) int {
+	flags := e.flagSet("info", "[-e encoding]", "Describe an encoding, including the fonts needed to display it.")
+	name := encodingFlag(flags)
+	if !e.parse(flags, args) {
+		return 2
+	}
+	enc, ok := e.encoding(*name)
+	if !ok {
+		return 2
+	}
+	return e.info(enc, *name)
+}
+
+func runRewrap(e env, args []string) int {
+	flags := e.flagSet("rewrap", "[-e encoding] [-w width]", "Validate padthai from stdin and rewrite it at a fixed width, without decoding.")
+	name := encodingFlag(flags)
+	width := flags.Int("w", 76, "line `width` in runes")
+	if !e.parse(flags, args) {
+		return 2
+	}
+	if *width <= 0 {
+		fmt.Fprintf(e.stderr, "padthai: invalid rewrap width %d\n", *width)
+		return 2
+	}
+	enc, ok := e.encoding(*name)
+	if !ok {
+		return 2
+	}
+	return e.rewrap(enc, *width)
+}
+
+// flagSet returns the flag set of the subcommand name, whose help shows
+// synopsis and description.
+func (e env) flagSet(name, synopsis, description string) *flag.FlagSet {
+	flags := flag.NewFlagSet("padthai "+name, flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: %s %s\n\n%s\n\n", flags.Name(), synopsis, description)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parse parses args with flags, and reports whether they are valid.
+// Commands read stdin, so most take no arguments besides flags.
+func (e env) parse(flags *flag.FlagSet, args []string) bool {
+	return e.parseArgs(flags, args, 0)
+}
+
+// parseArgs is like parse but allows up to max arguments after the flags.
+func (e env) parseArgs(flags *flag.FlagSet, args []string, max int) bool {
+	if err := flags.Parse(args); err != nil {
+		return false
+	}
+	if flags.NArg() > max {
+		fmt.Fprintf(e.stderr, "padthai: unexpected argument %q\n", flags.Arg(max))
+		flags.Usage()
+		return false
+	}
+	return true
+}
+
+func encodingFlag(flags *flag.FlagSet) *string {
+	return flags.String("e", "thai", "`encoding` to use: "+strings.Join(padthai.Names(), ", "))
+}
+
+func hexFlag(flags *flag.FlagSet) *bool {
+	return flags.Bool("hex", false, "express the binary side as hex: read hex to encode, or write hex when decoding")
+}
+
+// encoding returns the encoding called name, reporting on stderr if there is
+// none.
+func (e env) encoding(name string) (*padthai.Encoding, bool) {
+	enc, ok := padthai.ByName(name)
+	if !ok {
+		fmt.Fprintf(e.stderr, "padthai: unknown encoding %q (available: %s)\n", name, strings.Join(padthai.Names(), ", "))
+	}
+	return enc, ok
+}
+
+func (e env) encode(enc *padthai.Encoding, hexMode bool) int {
+	input, err := io.ReadAll(e.stdin)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "padthai: read error: %v\n", err)
+		return 1
+	}
+	if hexMode {
+		if input, err = hex.DecodeString(strings.Join(strings.Fields(string(input)), "")); err != nil {
+			fmt.Fprintf(e.stderr, "padthai: invalid hex input: %v\n", err)
+			return 1
 		}
+	}
+	encoded := enc.Encode(input)
+	if _, err := fmt.Fprint(e.stdout, encoded); err != nil {
+		fmt.Fprintf(e.stderr, "padthai: write error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (e env) decode(enc *padthai.Encoding, hexMode bool) int {
+	decoded, err := enc.DecodeReaderAll(e.stdin)
+	if err != nil {
+		e.inputError(err)
+		return 1
+	}
+	if hexMode {
+		decoded = []byte(hex.EncodeToString(decoded) + "\n")
+	}
+	if _, err := e.stdout.Write(decoded); err != nil {
+		fmt.Fprintf(e.stderr, "padthai: write error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// inputError reports err, from reading padthai from stdin, on stderr as a
+// decode error if the input is malformed, and as a read error otherwise.
+func (e env) inputError(err error) {
+	var de *padthai.DecodeError
+	if errors.As(err, &de) {
+		fmt.Fprintf(e.stderr, "padthai: decode error: %v\n", err)
 	} else {
-		input, err := io.ReadAll(os.Stdin)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "padthai: read error: %v\n", err)
-			os.Exit(1)
+		fmt.Fprintf(e.stderr, "padthai: read error: %v\n", err)
+	}
+}
+
+func (e env) info(enc *padthai.Encoding, name string) int {
+	i := enc.Info()
+	fmt.Fprintf(e.stdout, "%s: base %d, %g runes per input byte\n", name, i.Base, i.RunesPerByte)
+	fmt.Fprintf(e.stdout, "%s: %s\n", name, enc.RenderabilityHint())
+	return 0
+}
+
+func (e env) rewrap(enc *padthai.Encoding, width int) int {
+	// Spool the rewrapped text to a temporary file while the decoder
+	// validates the same bytes, and copy it out only once all of the input
+	// is known to be valid, so that garbage is never passed off as padthai
+	spool, err := os.CreateTemp("", "padthai-rewrap-*")
+	if err != nil {
+		fmt.Fprintf(e.stderr, "padthai: %v\n", err)
+		return 1
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	buf := bufio.NewWriter(spool)
+	w := &wrapWriter{enc: enc, w: buf, width: width}
+	_, err = io.Copy(io.Discard, enc.NewDecoder(io.TeeReader(e.stdin, w)))
+	if err == nil && w.err == nil {
+		w.Close()
+	}
+	if w.err == nil {
+		w.err = buf.Flush()
+	}
+	if w.err != nil {
+		fmt.Fprintf(e.stderr, "padthai: write error: %v\n", w.err)
+		return 1
+	}
+	if err != nil {
+		e.inputError(err)
+		return 1
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		fmt.Fprintf(e.stderr, "padthai: %v\n", err)
+		return 1
+	}
+	if _, err := io.Copy(e.stdout, spool); err != nil {
+		fmt.Fprintf(e.stderr, "padthai: write error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// wrapWriter writes the runes written to it that enc does not skip in lines
+// of at most width runes, each ending in a newline. Close ends the last line.
+type wrapWriter struct {
+	enc     *padthai.Encoding
+	w       io.Writer
+	width   int
+	n       int    // runes on the current line
+	partial []byte // the start of a rune split between writes
+	err     error
+}
+
+func (ww *wrapWriter) Write(p []byte) (int, error) {
+	if ww.err != nil {
+		return 0, ww.err
+	}
+	n := len(p)
+	if len(ww.partial) > 0 {
+		p = append(ww.partial, p...)
+		ww.partial = nil
+	}
+	for len(p) > 0 {
+		if !utf8.FullRune(p) {
+			ww.partial = append([]byte(nil), p...)
+			break
 		}
-		encoded := padthai.Encode(input)
-		if _, err := fmt.Fprint(os.Stdout, encoded); err != nil {
-			fmt.Fprintf(os.Stderr, "padthai: write error: %v\n", err)
-			os.Exit(1)
+		r, size := utf8.DecodeRune(p)
+		if !ww.enc.Skips(r) {
+			if ww.n == ww.width {
+				ww.write([]byte{'\n'})
+				ww.n = 0
+			}
+			ww.write(p[:size])
+			ww.n++
 		}
+		p = p[size:]
+	}
+	if ww.err != nil {
+		return 0, ww.err
+	}
+	return n, nil
+}
+
+// Close ends the last line. A rune still split at this point is invalid
+// input, which the decoder reports.
+func (ww *wrapWriter) Close() error {
+	if ww.n > 0 {
+		ww.write([]byte{'\n'})
+	}
+	return ww.err
+}
+
+// write writes p to the underlying writer unless an earlier write failed.
+func (ww *wrapWriter) write(p []byte) {
+	if ww.err == nil && len(p) > 0 {
+		_, ww.err = ww.w.Write(p)
 	}
 }
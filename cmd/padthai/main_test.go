@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf8"
+
+	"github.com/lynxnot/base-padthai/pkg/padthai"
+)
+
+// runCmd runs the command with args and stdin, and returns its exit code,
+// stdout and stderr.
+func runCmd(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestEncodeDecode(t *testing.T) {
+	code, encoded, stderr := runCmd(t, "Hello, World!")
+	if code != 0 {
+		t.Fatalf("encode: exit %d: %s", code, stderr)
+	}
+	if want := "ฉฃฆญฃญญฑอคฝธญณณญฃฅᨂᨁ"; encoded != want {
+		t.Errorf("encode: got %q, want %q", encoded, want)
+	}
+
+	code, decoded, stderr := runCmd(t, encoded, "-d")
+	if code != 0 {
+		t.Fatalf("decode: exit %d: %s", code, stderr)
+	}
+	if decoded != "Hello, World!" {
+		t.Errorf("decode: got %q", decoded)
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	input := bytes.Repeat([]byte("padthai"), 30)
+	encoded := padthai.Encode(input)
+
+	// Wrap awkwardly: uneven lines, stray spaces, CRLF and soft hyphens
+	var messy strings.Builder
+	for i, r := range []rune(encoded) {
+		if i%17 == 0 {
+			messy.WriteString(" \r\n")
+		}
+		if i%23 == 0 {
+			messy.WriteString("\u00ad")
+		}
+		messy.WriteRune(r)
+	}
+
+	code, out, stderr := runCmd(t, messy.String(), "--rewrap", "80")
+	if code != 0 {
+		t.Fatalf("rewrap: exit %d: %s", code, stderr)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	for i, line := range lines {
+		n := utf8.RuneCountInString(line)
+		if n > 80 || (i < len(lines)-1 && n != 80) {
+			t.Errorf("line %d has %d runes", i, n)
+		}
+	}
+
+	if strings.Contains(out, "\u00ad") {
+		t.Error("soft hyphens survive rewrapping")
+	}
+
+	decoded, err := padthai.Decode(out)
+	if err != nil {
+		t.Fatalf("decode rewrapped output: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("rewrap changed the decoded value")
+	}
+
+	// Runes split between reads are put back together
+	var stdout, errout bytes.Buffer
+	if code := run([]string{"rewrap", "-w", "80"}, iotest.OneByteReader(strings.NewReader(messy.String())), &stdout, &errout); code != 0 || stdout.String() != out {
+		t.Errorf("byte-by-byte rewrap: exit %d, output %q: %s", code, stdout.String(), errout.String())
+	}
+}
+
+func TestRewrapInvalid(t *testing.T) {
+	if code, _, _ := runCmd(t, "not padthai", "-rewrap", "80"); code != 1 {
+		t.Errorf("invalid input: exit %d, want 1", code)
+	}
+	// Nothing is written unless all of the input is valid, however much of
+	// it comes before the error
+	encoded := padthai.Encode(bytes.Repeat([]byte("Hello, World!"), 10000))
+	for _, input := range []string{encoded[:9] + "A" + encoded[9:], encoded[:len(encoded)-9] + "A" + encoded[len(encoded)-9:], encoded[:len(encoded)-1]} {
+		if code, out, stderr := runCmd(t, input, "rewrap"); code != 1 || out != "" || !strings.Contains(stderr, "decode error") {
+			t.Errorf("exit %d, %d bytes of output, stderr %q", code, len(out), stderr)
+		}
+	}
+	if code, _, _ := runCmd(t, "", "-d", "-rewrap", "80"); code != 2 {
+		t.Errorf("-d with -rewrap: exit %d, want 2", code)
+	}
+}
+
+func TestEncodingFlag(t *testing.T) {
+	code, encoded, stderr := runCmd(t, "Hello, World!", "-e", "compact")
+	if code != 0 {
+		t.Fatalf("encode: exit %d: %s", code, stderr)
+	}
+	if want := padthai.CompactEncoding.Encode([]byte("Hello, World!")); encoded != want {
+		t.Errorf("encode: got %q, want %q", encoded, want)
+	}
+
+	code, decoded, stderr := runCmd(t, encoded, "-e", "compact", "-d")
+	if code != 0 {
+		t.Fatalf("decode: exit %d: %s", code, stderr)
+	}
+	if decoded != "Hello, World!" {
+		t.Errorf("decode: got %q", decoded)
+	}
+
+	// Compact output is not valid Thai
+	if code, _, _ := runCmd(t, encoded, "-d"); code != 1 {
+		t.Errorf("decode with default encoding: exit %d, want 1", code)
+	}
+
+	code, _, stderr = runCmd(t, "", "-e", "klingon")
+	if code != 2 || !strings.Contains(stderr, "available: compact, nobaht, thai") {
+		t.Errorf("unknown encoding: exit %d, stderr %q", code, stderr)
+	}
+}
+
+func TestInfo(t *testing.T) {
+	code, out, stderr := runCmd(t, "", "-info")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	for _, want := range []string{"thai: base 48", "Thai (U+0E01", "Buginese (U+1A00"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not mention %q", out, want)
+		}
+	}
+}
+
+func TestHex(t *testing.T) {
+	code, encoded, stderr := runCmd(t, "48 65 6c 6c 6f\n2c20576f726c6421\n", "-hex")
+	if code != 0 {
+		t.Fatalf("encode: exit %d: %s", code, stderr)
+	}
+	if want := padthai.Encode([]byte("Hello, World!")); encoded != want {
+		t.Errorf("encode: got %q, want %q", encoded, want)
+	}
+
+	code, decoded, stderr := runCmd(t, encoded, "-d", "-hex")
+	if code != 0 {
+		t.Fatalf("decode: exit %d: %s", code, stderr)
+	}
+	if want := "48656c6c6f2c20576f726c6421\n"; decoded != want {
+		t.Errorf("decode: got %q, want %q", decoded, want)
+	}
+
+	if code, _, _ := runCmd(t, "4g", "-hex"); code != 1 {
+		t.Errorf("invalid hex: exit %d, want 1", code)
+	}
+	if code, _, _ := runCmd(t, "", "-hex", "-rewrap", "80"); code != 2 {
+		t.Errorf("-hex with -rewrap: exit %d, want 2", code)
+	}
+}
+
+func TestSubcommands(t *testing.T) {
+	input := "Hello, World!"
+	code, encoded, stderr := runCmd(t, input, "encode")
+	if code != 0 || encoded != padthai.Encode([]byte(input)) {
+		t.Fatalf("encode: exit %d, output %q: %s", code, encoded, stderr)
+	}
+	code, decoded, stderr := runCmd(t, encoded, "decode")
+	if code != 0 || decoded != input {
+		t.Errorf("decode: exit %d, output %q: %s", code, decoded, stderr)
+	}
+	code, decoded, stderr = runCmd(t, padthai.CompactEncoding.Encode([]byte(input)), "decode", "-e", "compact", "-hex")
+	if code != 0 || decoded != "48656c6c6f2c20576f726c6421\n" {
+		t.Errorf("decode -e compact -hex: exit %d, output %q: %s", code, decoded, stderr)
+	}
+
+	code, out, stderr := runCmd(t, encoded, "verify")
+	if code != 0 || out != "valid: 13 bytes\n" {
+		t.Errorf("verify: exit %d, output %q: %s", code, out, stderr)
+	}
+	if code, out, _ := runCmd(t, encoded[:len(encoded)-3], "verify"); code != 1 || out != "" {
+		t.Errorf("verify invalid input: exit %d, output %q", code, out)
+	}
+
+	code, out, stderr = runCmd(t, "", "info", "-e", "compact")
+	if code != 0 || !strings.HasPrefix(out, "compact: base 48") {
+		t.Errorf("info: exit %d, output %q: %s", code, out, stderr)
+	}
+
+	code, out, stderr = runCmd(t, encoded[:9]+"\n"+encoded[9:], "rewrap", "-w", "4")
+	if code != 0 || strings.Count(out, "\n") != 5 {
+		t.Errorf("rewrap: exit %d, output %q: %s", code, out, stderr)
+	}
+
+	// Flags belong to their subcommand, and only encode takes an argument
+	for _, args := range [][]string{
+		{"verify", "-hex"},
+		{"encode", "a.bin", "b.bin"},
+		{"rewrap", "-w", "0"},
+		{"decode", "-e", "klingon"},
+	} {
+		if code, _, _ := runCmd(t, "", args...); code != 2 {
+			t.Errorf("%q: exit %d, want 2", args, code)
+		}
+	}
+
+	// The mode flags still work, with a note to move on
+	code, decoded, stderr = runCmd(t, encoded, "-d")
+	if code != 0 || decoded != input || !strings.Contains(stderr, "padthai decode") {
+		t.Errorf("-d: exit %d, output %q, stderr %q", code, decoded, stderr)
+	}
+}
+
+func TestEncodeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.bin")
+	input := []byte("Hello, World!")
+	if err := os.WriteFile(path, input, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"thai", "compact"} {
+		enc, _ := padthai.ByName(name)
+		code, out, stderr := runCmd(t, "", "encode", "-e", name, path)
+		if code != 0 || out != enc.Encode(input) {
+			t.Errorf("encode -e %s file: exit %d, output %q: %s", name, code, out, stderr)
+		}
+	}
+
+	hexPath := filepath.Join(t.TempDir(), "input.hex")
+	if err := os.WriteFile(hexPath, []byte("48656c6c6f2c20576f726c6421\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, out, stderr := runCmd(t, "", "encode", "-hex", hexPath)
+	if code != 0 || out != padthai.Encode(input) {
+		t.Errorf("encode -hex file: exit %d, output %q: %s", code, out, stderr)
+	}
+
+	if code, _, _ := runCmd(t, "", "encode", filepath.Join(t.TempDir(), "missing")); code != 1 {
+		t.Errorf("missing file: exit %d, want 1", code)
+	}
+}
+
+func TestInputErrors(t *testing.T) {
+	encoded := padthai.Encode([]byte("Hello, World!"))
+	for _, args := range [][]string{{"decode"}, {"verify"}, {"rewrap"}} {
+		for _, input := range []string{encoded[:9] + "A" + encoded[9:], encoded[:9] + "\xff" + encoded[9:], encoded[:len(encoded)-3]} {
+			if code, _, stderr := runCmd(t, input, args...); code != 1 || !strings.HasPrefix(stderr, "padthai: decode error:") {
+				t.Errorf("%q, %q: exit %d, stderr %q", args, input, code, stderr)
+			}
+		}
+
+		// A failing read is not the input's fault
+		var stdout, stderr bytes.Buffer
+		stdin := iotest.TimeoutReader(strings.NewReader(encoded))
+		if code := run(args, iotest.OneByteReader(stdin), &stdout, &stderr); code != 1 || !strings.HasPrefix(stderr.String(), "padthai: read error:") {
+			t.Errorf("%q, failing read: exit %d, stderr %q", args, code, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package padthai
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrCheckDigit is returned by DecodeWithCheckDigit when the check rune does
+// not match the rest of the input.
+var ErrCheckDigit = errors.New("padthai: check digit mismatch")
+
+// checkModulus is the modulus of the check digit. It is prime, so that every
+// substitution of a digit and every transposition of adjacent digits changes
+// the weighted sum, unless the runes involved are equal modulo 47.
+const checkModulus = 47
+
+// checkDigit returns the check digit of the non-whitespace runes of s: their
+// values weighted by 1, 2, ..., 46, 1, 2, ... and summed modulo 47. Thai
+// digits are worth their index and Buginese runes 48 plus their nibble.
+func checkDigit(s string) int {
+	sum, w := 0, 1
+	for _, r := range s {
+		if isSpace(r) {
+			continue
+		}
+		v := thaiIndex[r]
+		if isBuginese(r) {
+			v = Base + int(r-bugineseStart)
+		}
+		sum = (sum + w*v) % checkModulus
+		if w++; w == checkModulus {
+			w = 1
+		}
+	}
+	return sum
+}
+
+// EncodeWithCheckDigit is like Encode but appends a check digit: one Thai
+// rune computed from the others, meant to catch mistakes when padthai is
+// read aloud or typed by hand. It detects every substitution of one rune by
+// another of the same alphabet, except ก for ฿ and back, and every
+// transposition of adjacent runes except that pair.
+//
+// Unlike a CRC, it is not meant to detect deliberate or bulk corruption.
+func EncodeWithCheckDigit(data []byte) string {
+	encoded := Encode(data)
+	return encoded + string(ThaiAlphabet[checkDigit(encoded)])
+}
+
+// DecodeWithCheckDigit decodes s as encoded by EncodeWithCheckDigit,
+// returning ErrCheckDigit if the check digit does not match. Whitespace is
+// skipped, as in Decode.
+func DecodeWithCheckDigit(s string) ([]byte, error) {
+	s = strings.TrimRightFunc(s, isSpace)
+	check, size := utf8.DecodeLastRuneInString(s)
+	if size == 0 {
+		return nil, &DecodeError{Kind: BadCheckDigit, ByteOffset: len(s), Rune: -1,
+			Err: fmt.Errorf("%w: input is empty", ErrCheckDigit)}
+	}
+	s = s[:len(s)-size]
+
+	data, err := Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if want := ThaiAlphabet[checkDigit(s)]; check != want {
+		return nil, &DecodeError{Kind: BadCheckDigit, RuneOffset: EncodedRuneLen(len(data)), ByteOffset: len(s), Rune: check,
+			Err: fmt.Errorf("%w: got %c, want %c", ErrCheckDigit, check, want)}
+	}
+	return data, nil
+}
+
+// DecodeRepair is like DecodeWithCheckDigit, but if s does not decode it
+// looks for the single transcription mistake that would explain it: first
+// a transposition of two adjacent runes, then a substitution of one rune by
+// another of the same alphabet. If exactly one such repair makes s decode
+// with a matching check digit, DecodeRepair returns the repaired data and a
+// note describing the repair, with positions counted in runes, whitespace
+// excluded. The note is empty if s needed no repair.
+//
+// A single check digit cannot tell which of several repairs is the right
+// one, so when more than one fits, DecodeRepair returns an ErrCheckDigit
+// rather than guess. That is common for substitutions, which a check digit
+// can only detect; transpositions are more often repairable.
+func DecodeRepair(s string) ([]byte, string, error) {
+	data, err := DecodeWithCheckDigit(s)
+	if err == nil {
+		return data, "", nil
+	}
+	runes := make([]rune, 0, len(s))
+	for _, r := range s {
+		if !isSpace(r) {
+			runes = append(runes, r)
+		}
+	}
+	if len(runes) == 0 {
+		return nil, "", err
+	}
+
+	var repairs repairSet
+	for i := 0; i+1 < len(runes); i++ {
+		if runes[i] == runes[i+1] {
+			continue
+		}
+		runes[i], runes[i+1] = runes[i+1], runes[i]
+		repairs.try(runes, func() string {
+			return fmt.Sprintf("swapped %c and %c at positions %d and %d", runes[i+1], runes[i], i, i+1)
+		})
+		runes[i], runes[i+1] = runes[i+1], runes[i]
+	}
+	if len(repairs) == 0 {
+		for i, orig := range runes {
+			alphabet := ThaiAlphabet[:]
+			if isBuginese(orig) {
+				alphabet = BugineseAlphabet[:]
+			}
+			for _, r := range alphabet {
+				if r == orig {
+					continue
+				}
+				runes[i] = r
+				repairs.try(runes, func() string {
+					return fmt.Sprintf("replaced %c with %c at position %d", orig, r, i)
+				})
+			}
+			runes[i] = orig
+		}
+	}
+
+	switch len(repairs) {
+	case 0:
+		return nil, "", err
+	case 1:
+		for _, rep := range repairs {
+			return rep.data, rep.note, nil
+		}
+	}
+	return nil, "", &DecodeError{Kind: BadCheckDigit, RuneOffset: len(runes) - 1, ByteOffset: -1, Rune: -1,
+		Err: fmt.Errorf("%w: %d different single repairs fit the input", ErrCheckDigit, len(repairs))}
+}
+
+// A repairSet collects the candidate repairs of DecodeRepair, keyed by
+// decoded data so that repairs with the same outcome count once.
+type repairSet map[string]repair
+
+type repair struct {
+	data []byte
+	note string
+}
+
+// try records the repair producing runes if they decode with a matching
+// check digit. note is only called for a successful repair.
+func (rs *repairSet) try(runes []rune, note func() string) {
+	data, err := DecodeWithCheckDigit(string(runes))
+	if err != nil {
+		return
+	}
+	if *rs == nil {
+		*rs = make(repairSet)
+	}
+	if _, ok := (*rs)[string(data)]; !ok {
+		(*rs)[string(data)] = repair{data, note()}
+	}
+}
@@ -0,0 +1,123 @@
+package padthai
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCheckDigitRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 13, 100} {
+		input := make([]byte, size)
+		_, _ = io.ReadFull(rand.Reader, input)
+
+		encoded := EncodeWithCheckDigit(input)
+		if got, want := len([]rune(encoded)), EncodedRuneLen(size)+1; got != want {
+			t.Errorf("size %d: expected %d runes, got %d", size, want, got)
+		}
+		decoded, err := DecodeWithCheckDigit(wrapLines(encoded, 5) + "\n")
+		if err != nil {
+			t.Fatalf("size %d: decode: %v", size, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Errorf("size %d: roundtrip mismatch", size)
+		}
+	}
+
+	if _, err := DecodeWithCheckDigit(" "); !errors.Is(err, ErrCheckDigit) {
+		t.Errorf("blank input: expected ErrCheckDigit, got %v", err)
+	}
+}
+
+func TestCheckDigitDetectsMutations(t *testing.T) {
+	input := make([]byte, 31)
+	_, _ = io.ReadFull(rand.Reader, input)
+	runes := []rune(EncodeWithCheckDigit(input))
+
+	// Replace each rune in turn by every other rune of its alphabet
+	total, missed := 0, 0
+	for i, orig := range runes {
+		alphabet := ThaiAlphabet[:]
+		if isBuginese(orig) {
+			alphabet = BugineseAlphabet[:]
+		}
+		for _, r := range alphabet {
+			if r == orig {
+				continue
+			}
+			mutated := append([]rune(nil), runes...)
+			mutated[i] = r
+			total++
+			if _, err := DecodeWithCheckDigit(string(mutated)); err == nil {
+				missed++
+			}
+		}
+	}
+	if missed*100 > total {
+		t.Errorf("substitutions: %d of %d undetected", missed, total)
+	}
+
+	// Swap each pair of adjacent, different runes
+	total, missed = 0, 0
+	for i := 0; i+1 < len(runes); i++ {
+		if runes[i] == runes[i+1] {
+			continue
+		}
+		swapped := append([]rune(nil), runes...)
+		swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+		total++
+		if _, err := DecodeWithCheckDigit(string(swapped)); err == nil {
+			missed++
+		}
+	}
+	if missed*100 > total*5 {
+		t.Errorf("transpositions: %d of %d undetected", missed, total)
+	}
+}
+
+func TestDecodeRepair(t *testing.T) {
+	input := []byte("padthai, transcribed by hand")
+	encoded := EncodeWithCheckDigit(input)
+	runes := []rune(encoded)
+
+	data, note, err := DecodeRepair(encoded)
+	if err != nil || note != "" || !bytes.Equal(data, input) {
+		t.Fatalf("intact input: got %q, note %q, err %v", data, note, err)
+	}
+
+	// A repair is never wrong: it either restores the input or refuses
+	repaired := 0
+	for i := 0; i+1 < len(runes); i++ {
+		if runes[i] == runes[i+1] {
+			continue
+		}
+		swapped := append([]rune(nil), runes...)
+		swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+		data, note, err := DecodeRepair(string(swapped))
+		switch {
+		case err == nil:
+			if !bytes.Equal(data, input) {
+				t.Fatalf("swap at %d: repaired to %q (%s)", i, data, note)
+			}
+			repaired++
+		case !errors.Is(err, ErrCheckDigit):
+			t.Fatalf("swap at %d: expected ErrCheckDigit, got %v", i, err)
+		}
+	}
+	if repaired*2 < len(runes) {
+		t.Errorf("repaired %d of %d transpositions", repaired, len(runes)-1)
+	}
+
+	swapped := append([]rune(nil), runes...)
+	swapped[3], swapped[4] = swapped[4], swapped[3]
+	data, note, err = DecodeRepair(string(swapped))
+	if err != nil || !bytes.Equal(data, input) {
+		t.Fatalf("swap at 3: got %q, err %v", data, err)
+	}
+	if want := "positions 3 and 4"; !strings.Contains(note, want) {
+		t.Errorf("swap at 3: note %q does not mention %q", note, want)
+	}
+}
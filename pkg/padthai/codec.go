@@ -0,0 +1,40 @@
+package padthai
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// Codec is the common shape of binary-to-text encodings, which *Encoding
+// shares with small adapters around encoding/base64 and the like, so that
+// generic code can take any of them.
+type Codec interface {
+	// Encode returns the text encoding of data.
+	Encode(data []byte) string
+	// Decode returns the bytes encoded by s.
+	Decode(s string) ([]byte, error)
+	// EncodedLen returns the length in bytes of the encoding of n bytes.
+	EncodedLen(n int) int
+}
+
+var _ Codec = (*Encoding)(nil)
+
+// EncodedLen returns the length in bytes of the UTF-8 encoding of n input
+// bytes with enc, as EncodedByteLen does, for Codec. Where EncodedByteLen
+// returns -1 because the length depends on the data, EncodedLen returns an
+// upper bound instead, so that it can always size a buffer. It returns -1 if
+// n is negative or the length would overflow an int.
+func (enc *Encoding) EncodedLen(n int) int {
+	runes, size := enc.encodedLen(n)
+	switch {
+	case size >= 0:
+		return size
+	case runes >= 0:
+		// Runes of varying widths
+		return runes * utf8.UTFMax
+	case n < 0 || n/2 > (math.MaxInt/utf8.UTFMax-10)/3:
+		return -1
+	}
+	// A PadEncoding emitting a varying number of runes, at most 8
+	return (n/2*3+8)*utf8.UTFMax + len(enc.term)
+}
@@ -0,0 +1,55 @@
+package padthai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+// base64Codec adapts encoding/base64 to Codec, as a caller's plumbing would.
+type base64Codec struct{ *base64.Encoding }
+
+func (c base64Codec) Encode(data []byte) string {
+	return c.EncodeToString(data)
+}
+
+func (c base64Codec) Decode(s string) ([]byte, error) {
+	return c.DecodeString(s)
+}
+
+func TestCodec(t *testing.T) {
+	main, pad := latinAlphabets()
+	main[0] = 'ก' // mixed widths: EncodedLen is a bound
+	mixed, err := NewEncoding(main, pad)
+	if err != nil {
+		t.Fatalf("NewEncoding: %v", err)
+	}
+
+	codecs := map[string]Codec{
+		"std":     StdEncoding,
+		"compact": CompactEncoding,
+		"mixed":   mixed,
+		"hexpad":  withHexPad(t),
+		"base64":  base64Codec{base64.StdEncoding},
+	}
+	input := []byte("Hello, World!")
+	for name, c := range codecs {
+		for n := 0; n <= len(input); n++ {
+			encoded := c.Encode(input[:n])
+			if max := c.EncodedLen(n); len(encoded) > max || name != "mixed" && len(encoded) != max {
+				t.Errorf("%s: %d bytes encode to %d bytes, EncodedLen %d", name, n, len(encoded), max)
+			}
+			decoded, err := c.Decode(encoded)
+			if err != nil {
+				t.Fatalf("%s: decode: %v", name, err)
+			}
+			if !bytes.Equal(decoded, input[:n]) {
+				t.Errorf("%s: roundtrip mismatch", name)
+			}
+		}
+	}
+
+	if got := StdEncoding.EncodedLen(-1); got != -1 {
+		t.Errorf("EncodedLen(-1) = %d, want -1", got)
+	}
+}
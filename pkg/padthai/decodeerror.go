@@ -0,0 +1,88 @@
+package padthai
+
+import "fmt"
+
+// ErrorKind classifies a DecodeError.
+type ErrorKind int
+
+const (
+	InvalidChar   ErrorKind = iota + 1 // a rune that is not part of the encoding where it appears
+	InvalidLength                      // a number of runes no encoding has, or a missing terminator
+	BadPadding                         // padding that is malformed or misplaced
+	OutOfRange                         // valid digits whose value does not fit their bytes
+	InvalidUTF8                        // input that is not valid UTF-8
+	BadCheckDigit                      // a check digit that does not match, or no single repair
+	NotCanonical                       // valid input that is not in canonical form
+)
+
+var kindNames = [...]string{
+	InvalidChar:   "invalid character",
+	InvalidLength: "invalid length",
+	BadPadding:    "bad padding",
+	OutOfRange:    "out of range",
+	InvalidUTF8:   "invalid UTF-8",
+	BadCheckDigit: "bad check digit",
+	NotCanonical:  "not canonical",
+}
+
+func (k ErrorKind) String() string {
+	if k > 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// DecodeError describes malformed input to a decoder, with what a tool needs
+// to point at the problem. Every exported function that decodes padthai,
+// from Decode to DecodeDigits and DecodeWithCheckDigit, returns it for every
+// error about its input, possibly wrapped, as by LineError; use errors.As to
+// get at it. Errors from the reader or writer of a streaming decoder are
+// passed on as they are. A DecodeError wraps a detailed error, which in turn
+// wraps the matching sentinel, such as ErrInvalidPadding or ErrNonCanonical,
+// so errors.Is works as before.
+type DecodeError struct {
+	Kind ErrorKind
+
+	// RuneOffset is the position of the offending rune among the runes the
+	// decoder keeps, skipped runes such as whitespace excluded, as in error
+	// messages. For InvalidLength, it is where the input falls short.
+	RuneOffset int
+
+	// ByteOffset is the offset in the input of the offending rune or byte,
+	// or -1 where the decoder does not track it, as for DecodeRunes and the
+	// streaming decoders. Invalid UTF-8 is always reported with its offset.
+	ByteOffset int
+
+	// Rune is the offending rune, utf8.RuneError for InvalidUTF8, or -1 if
+	// the error is not about a single rune.
+	Rune rune
+
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// withByteOffset returns err with the byte offset of a *DecodeError mapped
+// by off, for decoders that pass a transformed input to Decode, or unchanged
+// if it is not a *DecodeError.
+func withByteOffset(err error, off func(int) int) error {
+	de, ok := err.(*DecodeError)
+	if !ok || de.ByteOffset < 0 {
+		return err
+	}
+	c := *de
+	c.ByteOffset = off(c.ByteOffset)
+	return &c
+}
+
+// corrupt returns a *DecodeError of the given kind for the rune r at
+// position pos among the runes kept, whose message is that of err.
+func corrupt(kind ErrorKind, pos int, r rune, err error) error {
+	return &DecodeError{Kind: kind, RuneOffset: pos, ByteOffset: -1, Rune: r, Err: err}
+}
@@ -0,0 +1,171 @@
+package padthai
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDecodeError(t *testing.T) {
+	encoded := Encode([]byte("Hello, World!")) // 18 Thai runes, then 2 Buginese
+	for _, tt := range []struct {
+		input      string
+		kind       ErrorKind
+		sentinel   error
+		runeOffset int
+		byteOffset int
+		r          rune
+	}{
+		{encoded[:9] + " \nA" + encoded[12:], InvalidChar, ErrInvalidCharacter, 3, 11, 'A'},
+		{encoded[:6] + "\ufe0f" + encoded[6:], InvalidChar, ErrVariationSelector, 2, 6, '\ufe0f'},
+		{encoded[:12], InvalidLength, ErrInvalidLength, 4, 12, -1},
+		{encoded[:len(encoded)-3], BadPadding, ErrTruncatedPadding, 18, 54, 'ᨂ'},
+		{encoded[:3] + "ᨁ" + encoded[6:], BadPadding, ErrInvalidPadding, 1, 3, 'ᨁ'},
+		{"กกก ฯฯฯ", OutOfRange, ErrNonCanonical, 3, 10, 'ฯ'},
+		{encoded[:9] + "\xff" + encoded[9:], InvalidUTF8, ErrInvalidUTF8, 3, 9, utf8.RuneError},
+	} {
+		_, err := Decode(tt.input)
+		var de *DecodeError
+		if !errors.As(err, &de) {
+			t.Errorf("Decode(%q): got %v, want a *DecodeError", tt.input, err)
+			continue
+		}
+		if de.Kind != tt.kind || de.RuneOffset != tt.runeOffset || de.ByteOffset != tt.byteOffset || de.Rune != tt.r {
+			t.Errorf("Decode(%q): got %v at rune %d, byte %d, rune %U; want %v at rune %d, byte %d, rune %U",
+				tt.input, de.Kind, de.RuneOffset, de.ByteOffset, de.Rune, tt.kind, tt.runeOffset, tt.byteOffset, tt.r)
+		}
+		if !errors.Is(err, tt.sentinel) {
+			t.Errorf("Decode(%q): %v does not match %v", tt.input, err, tt.sentinel)
+		}
+		if de.Error() != de.Err.Error() {
+			t.Errorf("Decode(%q): message %q differs from the wrapped %q", tt.input, de.Error(), de.Err.Error())
+		}
+	}
+
+	// Without a string, the byte offset is unknown
+	_, err := DecodeRunes([]rune("กขA"))
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Kind != InvalidChar || de.RuneOffset != 2 || de.ByteOffset != -1 {
+		t.Errorf("DecodeRunes: got %#v", err)
+	}
+
+	// Every decoder reports invalid UTF-8 the same way
+	bad := encoded[:9] + "\xff" + encoded[9:]
+	for name, decode := range map[string]func() error{
+		"DecodeRuneReader": func() error {
+			_, err := DecodeRuneReader(strings.NewReader(bad))
+			return err
+		},
+		"DecodeSeq": func() error {
+			for _, err := range DecodeSeq(bad) {
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		"DecodeUpTo": func() error {
+			_, _, err := DecodeUpTo(bad, 30)
+			return err
+		},
+	} {
+		err := decode()
+		if !errors.As(err, &de) || de.Kind != InvalidUTF8 || de.RuneOffset != 3 || de.ByteOffset != 9 || de.Rune != utf8.RuneError {
+			t.Errorf("%s: got %#v", name, err)
+		}
+		if !errors.Is(err, ErrInvalidUTF8) {
+			t.Errorf("%s: %v does not match ErrInvalidUTF8", name, err)
+		}
+	}
+
+	if got := OutOfRange.String(); got != "out of range" {
+		t.Errorf("OutOfRange.String() = %q", got)
+	}
+}
+
+func TestDecodersReturnDecodeError(t *testing.T) {
+	encoded := Encode([]byte("Hello, World!"))
+	bad := encoded[:9] + "A" + encoded[9:]
+	checked := []rune(EncodeWithCheckDigit([]byte("Hello, World!")))
+	checked[4] = ThaiAlphabet[(slices.Index(ThaiAlphabet[:], checked[4])+1)%Base]
+
+	for name, decode := range map[string]func() error{
+		"Decode":           func() error { _, err := Decode(bad); return err },
+		"Encoding.Decode":  func() error { _, err := CompactEncoding.Decode(bad); return err },
+		"DecodeStrict":     func() error { _, err := DecodeStrict(" \n"); return err },
+		"DecodeMeta":       func() error { _, _, err := DecodeMeta(bad); return err },
+		"DecodeRunes":      func() error { _, err := DecodeRunes([]rune(bad)); return err },
+		"DecodeFixedWidth": func() error { _, err := DecodeFixedWidth(bad, '.'); return err },
+		"DecodeReaderAll":  func() error { _, err := DecodeReaderAll(strings.NewReader(bad)); return err },
+		"DecodeReaderTo": func() error {
+			_, err := DecodeReaderTo(io.Discard, strings.NewReader(bad))
+			return err
+		},
+		"DecodeToWriter":   func() error { _, err := DecodeToWriter(io.Discard, bad); return err },
+		"NewBytesReader":   func() error { _, err := NewBytesReader(bad); return err },
+		"DecodeRuneReader": func() error { _, err := DecodeRuneReader(strings.NewReader(bad)); return err },
+		"DecodeSeq": func() error {
+			for _, err := range DecodeSeq(bad) {
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		"IncrementalDecoder": func() error {
+			d := NewIncrementalDecoder()
+			for _, r := range bad {
+				if _, err := d.Feed(r); err != nil {
+					return err
+				}
+			}
+			_, err := d.Finish()
+			return err
+		},
+		"DecodeUpTo":       func() error { _, _, err := DecodeUpTo(bad, 30); return err },
+		"DecodedLenBounds": func() error { _, _, err := DecodedLenBounds(encoded[:12]); return err },
+		"DecodeVerbose":    func() error { _, _, err := DecodeVerbose(bad); return err },
+		"DecodeCanonical":  func() error { _, _, err := DecodeCanonical(" "+encoded, false); return err },
+		"DecodeOCR":        func() error { _, _, err := DecodeOCR(bad); return err },
+		"DecodeWithLayout": func() error { _, _, err := DecodeWithLayout(bad); return err },
+		"DecodeLines":      func() error { _, err := DecodeLines(strings.NewReader(encoded + "\n" + bad)); return err },
+		"DecodeWords":      func() error { _, err := DecodeWords(encoded + " " + bad); return err },
+		"DecodeMultiPadded": func() error {
+			_, err := DecodeMultiPadded(bad)
+			return err
+		},
+		"DecodeHiddenBits":     func() error { _, _, err := DecodeHiddenBits(encoded + "   "); return err },
+		"DecodeWithCheckDigit": func() error { _, err := DecodeWithCheckDigit(encoded + "ก"); return err },
+		"DecodeRepair":         func() error { _, _, err := DecodeRepair(string(checked)); return err },
+		"DecodeNumber":         func() error { _, err := DecodeNumber[uint32](encoded); return err },
+		"DecodeDenseInt":       func() error { _, err := DecodeDenseInt("กAก"); return err },
+		"DecodeDigits":         func() error { _, err := DecodeDigits([][3]int{{47, 47, 47}}, nil); return err },
+		"DecodeToDigits":       func() error { _, _, err := DecodeToDigits(bad); return err },
+		"DecodeURLEscaped":     func() error { _, err := DecodeURLEscaped(url.QueryEscape(encoded) + "%zz"); return err },
+		"DecodeUTF16":          func() error { _, err := DecodeUTF16(append(EncodeToUTF16([]byte("Hi")), 0xD800)); return err },
+		"Transcode":            func() error { _, err := Transcode(bad, StdEncoding, CompactEncoding); return err },
+	} {
+		err := decode()
+		var de *DecodeError
+		if !errors.As(err, &de) {
+			t.Errorf("%s: got %v, want a *DecodeError", name, err)
+		}
+	}
+
+	// Offsets are those of the input given, not of text derived from it
+	units := EncodeToUTF16([]byte("Hi"))
+	units[1] = 'A'
+	_, err := DecodeUTF16(units)
+	var de *DecodeError
+	if !errors.As(err, &de) || de.ByteOffset != 1 {
+		t.Errorf("DecodeUTF16: got %#v, want byte offset 1, in code units", err)
+	}
+	_, err = DecodeURLEscaped("%E0%B8%81+%zz")
+	if !errors.As(err, &de) || de.ByteOffset != 10 || de.RuneOffset != 1 {
+		t.Errorf("DecodeURLEscaped: got %#v, want byte offset 10, rune 1", err)
+	}
+}
@@ -0,0 +1,105 @@
+package padthai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dump returns a human-readable table showing how Encode maps data to runes,
+// in the spirit of hex.Dump. Each line holds the offset of a byte pair, the
+// pair in hex, its three base-48 digits and the resulting Thai runes; a
+// trailing odd byte is shown with its two nibbles and Buginese runes:
+//
+//	00000000  48 69  [ 8  2  9]  ฉฃช
+//	00000002  21     [ 2  1]     ᨂᨁ
+//
+// Dump is a diagnostic aid; its format may change.
+func Dump(data []byte) string {
+	var sb strings.Builder
+	i := 0
+	for ; i+1 < len(data); i += 2 {
+		val := int(data[i])<<8 | int(data[i+1])
+		d0, d1, d2 := val/(Base*Base), val/Base%Base, val%Base
+		fmt.Fprintf(&sb, "%08x  %02x %02x  [%2d %2d %2d]  %c%c%c\n",
+			i, data[i], data[i+1], d0, d1, d2,
+			ThaiAlphabet[d0], ThaiAlphabet[d1], ThaiAlphabet[d2])
+	}
+	if i < len(data) {
+		hi, lo := data[i]>>4, data[i]&0x0f
+		fmt.Fprintf(&sb, "%08x  %02x     [%2d %2d]     %c%c\n",
+			i, data[i], hi, lo, BugineseAlphabet[hi], BugineseAlphabet[lo])
+	}
+	return sb.String()
+}
+
+// EncodeDigits returns the digits Encode produces for data, before they are
+// mapped to runes: the three base-48 digits of each byte pair, most
+// significant first, and the two nibbles of a trailing odd byte, if any. It
+// lets tests assert on the numeric structure of an encoding directly.
+func EncodeDigits(data []byte) (triplets [][3]int, pad []int) {
+	triplets = make([][3]int, 0, len(data)/2)
+	i := 0
+	for ; i+1 < len(data); i += 2 {
+		val := int(data[i])<<8 | int(data[i+1])
+		triplets = append(triplets, [3]int{val / (Base * Base), val / Base % Base, val % Base})
+	}
+	if i < len(data) {
+		pad = []int{int(data[i] >> 4), int(data[i] & 0x0f)}
+	}
+	return triplets, pad
+}
+
+// DecodeDigits is the inverse of EncodeDigits. It returns ErrNonCanonical if
+// a triplet is not canonical, as reported by IsCanonicalTriplet, and an error
+// if pad is neither empty nor two nibbles.
+func DecodeDigits(triplets [][3]int, pad []int) ([]byte, error) {
+	out := make([]byte, 0, len(triplets)*2+1)
+	for i, t := range triplets {
+		if !IsCanonicalTriplet(t[0], t[1], t[2]) {
+			return nil, corrupt(OutOfRange, 3*i, -1, fmt.Errorf("%w: triplet %d has digits %v", ErrNonCanonical, i, t))
+		}
+		val := (t[0]*Base+t[1])*Base + t[2]
+		out = append(out, byte(val>>8), byte(val))
+	}
+	switch {
+	case len(pad) == 0:
+	case len(pad) == 2 && pad[0] >= 0 && pad[0] < PadBase && pad[1] >= 0 && pad[1] < PadBase:
+		out = append(out, byte(pad[0]<<4|pad[1]))
+	default:
+		return nil, corrupt(BadPadding, 3*len(triplets), -1, fmt.Errorf("%w: padding digits %v, want 2 nibbles", ErrInvalidPadding, pad))
+	}
+	return out, nil
+}
+
+// DecodeToDigits returns the digit values of the Thai runes of s and the
+// nibble values of its Buginese padding runes, in order, without combining
+// them into bytes: the intermediate form of Decode, for analysis tools and
+// visualizations. Whitespace is skipped as in Decode.
+//
+// Only the runes themselves are checked: it returns an error for a rune of
+// neither alphabet, or a Thai rune after padding, but not for lengths or
+// values that Decode would reject, which is what analyzing broken input
+// requires.
+func DecodeToDigits(s string) (thai []int, pad []int, err error) {
+	thai = make([]int, 0, keptRuneCount(s))
+	pos := 0
+	for i, r := range s {
+		if isSpace(r) {
+			continue
+		}
+		switch d, ok := thaiIndex[r]; {
+		case ok && len(pad) > 0:
+			return nil, nil, &DecodeError{Kind: BadPadding, RuneOffset: pos, ByteOffset: i, Rune: r,
+				Err: fmt.Errorf("%w: digit %c at position %d follows padding", ErrInvalidPadding, r, pos)}
+		case ok:
+			thai = append(thai, d)
+		case isBuginese(r):
+			pad = append(pad, int(r-bugineseStart))
+		default:
+			return nil, nil, &DecodeError{Kind: InvalidChar, RuneOffset: pos, ByteOffset: i, Rune: r,
+				Err: fmt.Errorf("%w %U at position %d", ErrInvalidCharacter, r, pos)}
+		}
+		pos++
+	}
+	return thai, pad, nil
+}
@@ -0,0 +1,95 @@
+package padthai
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	want := "" +
+		"00000000  48 69  [ 8  2  9]  ฉฃช\n" +
+		"00000002  21     [ 2  1]     ᨂᨁ\n"
+	if got := Dump([]byte("Hi!")); got != want {
+		t.Errorf("Dump:\n got %q\nwant %q", got, want)
+	}
+
+	if got := Dump(nil); got != "" {
+		t.Errorf("Dump(nil) = %q, want empty", got)
+	}
+}
+
+func TestDumpMatchesEncode(t *testing.T) {
+	input := []byte{0x00, 0x00, 0xFF, 0xFF, 0x7F}
+
+	// The rune column of the dump, concatenated, is the encoding
+	var runes strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(Dump(input), "\n"), "\n") {
+		fields := strings.Fields(line)
+		runes.WriteString(fields[len(fields)-1])
+	}
+	if got, want := runes.String(), Encode(input); got != want {
+		t.Errorf("dump runes = %q, want %q", got, want)
+	}
+}
+
+func TestEncodeDigits(t *testing.T) {
+	triplets, pad := EncodeDigits([]byte("Hi!"))
+	if want := [][3]int{{8, 2, 9}}; !slices.Equal(triplets, want) {
+		t.Errorf("triplets = %v, want %v", triplets, want)
+	}
+	if want := []int{2, 1}; !slices.Equal(pad, want) {
+		t.Errorf("pad = %v, want %v", pad, want)
+	}
+
+	input := []byte{0x00, 0x00, 0xFF, 0xFF, 0x7F, 0x12}
+	triplets, pad = EncodeDigits(input)
+	if pad != nil {
+		t.Errorf("even input: pad = %v", pad)
+	}
+	decoded, err := DecodeDigits(triplets, pad)
+	if err != nil {
+		t.Fatalf("DecodeDigits: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("roundtrip mismatch: got %x, want %x", decoded, input)
+	}
+
+	if _, err := DecodeDigits([][3]int{{47, 47, 47}}, nil); !errors.Is(err, ErrNonCanonical) {
+		t.Errorf("non-canonical triplet: got %v", err)
+	}
+	for _, pad := range [][]int{{1}, {1, 16}, {-1, 0}, {1, 2, 3}} {
+		if _, err := DecodeDigits(nil, pad); !errors.Is(err, ErrInvalidPadding) {
+			t.Errorf("pad %v: expected ErrInvalidPadding, got %v", pad, err)
+		}
+	}
+}
+
+func TestDecodeToDigits(t *testing.T) {
+	// "Hi" is 0x4869 = 18537 = 8*48² + 2*48 + 9, and "!" is 0x21
+	thai, pad, err := DecodeToDigits(Encode([]byte("Hi!")) + "\n")
+	if err != nil {
+		t.Fatalf("DecodeToDigits: %v", err)
+	}
+	if want := []int{8, 2, 9}; !slices.Equal(thai, want) {
+		t.Errorf("thai = %v, want %v", thai, want)
+	}
+	if want := []int{2, 1}; !slices.Equal(pad, want) {
+		t.Errorf("pad = %v, want %v", pad, want)
+	}
+
+	// Structure Decode rejects is still analyzed
+	thai, pad, err = DecodeToDigits("ฮฮ ฮ฿ᨏ")
+	if err != nil || !slices.Equal(thai, []int{45, 45, 45, 47}) || !slices.Equal(pad, []int{15}) {
+		t.Errorf("got %v, %v, %v", thai, pad, err)
+	}
+
+	if _, _, err := DecodeToDigits("ᨀก"); !errors.Is(err, ErrInvalidPadding) {
+		t.Errorf("digit after padding: got %v", err)
+	}
+	if _, _, err := DecodeToDigits("กx"); err == nil {
+		t.Errorf("invalid character: no error")
+	}
+}
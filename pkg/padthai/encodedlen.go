@@ -0,0 +1,93 @@
+package padthai
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// EncodedRuneLen returns the number of runes in enc's encoding of n input
+// bytes, taking its tail scheme and terminator into account. It returns -1
+// if n is negative or the result would overflow an int, or if it depends on
+// the data: with a PadEncoding that emits a varying number of runes.
+func (enc *Encoding) EncodedRuneLen(n int) int {
+	runes, _ := enc.encodedLen(n)
+	return runes
+}
+
+// EncodedByteLen returns the length in bytes of the UTF-8 encoding of n input
+// bytes with enc, using the actual widths of its runes, for instance to set
+// a Content-Length header. It returns -1 where EncodedRuneLen does, and also
+// if the runes of an alphabet differ in width, since the length then depends
+// on the data.
+func (enc *Encoding) EncodedByteLen(n int) int {
+	_, size := enc.encodedLen(n)
+	return size
+}
+
+// encodedLen implements EncodedRuneLen and EncodedByteLen.
+func (enc *Encoding) encodedLen(n int) (runes, size int) {
+	// Every rune takes at most 4 bytes, and a tail or terminator at most 10
+	// runes on top of the digits
+	if n < 0 || n/2 > (math.MaxInt/utf8.UTFMax-10)/3 {
+		return -1, -1
+	}
+	digits := n / 2 * 3
+	digitWidth := uniformWidth(enc.alphabet)
+
+	// The trailing odd byte, as digits and padding runes
+	tailDigits, tailPad, padWidth := 0, 0, uniformWidth(enc.pad[:])
+	if n%2 == 1 {
+		switch {
+		case enc.tail == tailMixed:
+			tailDigits, tailPad = 1, 1
+		case enc.tail == tailZero:
+			tailDigits = 4
+		case enc.tail == tailThai:
+			tailDigits = 2
+		case enc.padEnc != nil:
+			tailPad, padWidth = padEncodingLen(enc.padEnc)
+			if tailPad < 0 {
+				return -1, -1
+			}
+		default:
+			tailPad = 2
+		}
+	}
+
+	runes = digits + tailDigits + tailPad + utf8.RuneCountInString(enc.term)
+	size = -1
+	if digitWidth > 0 && (tailPad == 0 || padWidth > 0) {
+		size = (digits+tailDigits)*digitWidth + tailPad*padWidth + len(enc.term)
+	}
+	return runes, size
+}
+
+// uniformWidth returns the UTF-8 width shared by all runes, or -1 if they
+// differ.
+func uniformWidth(runes []rune) int {
+	width := utf8.RuneLen(runes[0])
+	for _, r := range runes[1:] {
+		if utf8.RuneLen(r) != width {
+			return -1
+		}
+	}
+	return width
+}
+
+// padEncodingLen returns the number of runes p emits for every byte and their
+// shared UTF-8 width; the width is -1 if it varies, and both are -1 if the
+// number of runes varies.
+func padEncodingLen(p PadEncoding) (runes, width int) {
+	first := p.Encode(0)
+	runes, width = len(first), uniformWidth(first)
+	for b := 1; b < 256; b++ {
+		enc := p.Encode(byte(b))
+		if len(enc) != runes {
+			return -1, -1
+		}
+		if width > 0 && uniformWidth(enc) != width {
+			width = -1
+		}
+	}
+	return runes, width
+}
@@ -0,0 +1,62 @@
+package padthai
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEncodingEncodedLen(t *testing.T) {
+	main, pad := latinAlphabets()
+	latin, err := NewEncoding(main, pad)
+	if err != nil {
+		t.Fatalf("NewEncoding: %v", err)
+	}
+
+	encodings := map[string]*Encoding{
+		"std":        StdEncoding,
+		"compact":    CompactEncoding,
+		"latin":      latin,
+		"words":      StdEncoding.WithGroupSize(4),
+		"terminated": CompactEncoding.WithExplicitTerminator(),
+		"mixed":      CompactEncoding.WithMixedTail(),
+		"zero":       StdEncoding.WithZeroPadTrailer(),
+		"hex":        withHexPad(t),
+	}
+	for name, enc := range encodings {
+		for size := 0; size <= 9; size++ {
+			encoded := enc.Encode(make([]byte, size))
+			if got, want := enc.EncodedRuneLen(size), len([]rune(encoded)); got != want {
+				t.Errorf("%s: EncodedRuneLen(%d) = %d, want %d", name, size, got, want)
+			}
+			if got, want := enc.EncodedByteLen(size), len(encoded); got != want {
+				t.Errorf("%s: EncodedByteLen(%d) = %d, want %d", name, size, got, want)
+			}
+		}
+	}
+
+	if got, want := CompactEncoding.EncodedByteLen(1000), EncodedByteLen(1000)*2/3; got != want {
+		t.Errorf("compact: EncodedByteLen(1000) = %d, want %d", got, want)
+	}
+}
+
+func TestEncodingEncodedLenUnknown(t *testing.T) {
+	// Mixing 1-byte and 3-byte digits makes the length data-dependent
+	main, pad := latinAlphabets()
+	main[0] = 'ก'
+	mixed, err := NewEncoding(main, pad)
+	if err != nil {
+		t.Fatalf("NewEncoding: %v", err)
+	}
+	if got := mixed.EncodedByteLen(2); got != -1 {
+		t.Errorf("mixed widths: EncodedByteLen(2) = %d, want -1", got)
+	}
+	if got := mixed.EncodedRuneLen(2); got != 3 {
+		t.Errorf("mixed widths: EncodedRuneLen(2) = %d, want 3", got)
+	}
+
+	for _, n := range []int{-1, math.MaxInt} {
+		if got := StdEncoding.EncodedByteLen(n); got != -1 {
+			t.Errorf("EncodedByteLen(%d) = %d, want -1", n, got)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package padthai
+
+import "crypto/sha256"
+
+// Fingerprint returns a short padthai identifier for data: the first nBytes
+// bytes of its SHA-256 hash, encoded. It is stable across versions and
+// platforms, for use as a cache key or a label that is easy to compare by
+// eye; 8 bytes give 12 runes.
+//
+// A fingerprint identifies data, it does not authenticate it. Truncation
+// leaves only 8*nBytes bits, so short fingerprints can be forced to collide
+// and must not be relied on for security.
+//
+// Fingerprint panics if nBytes is not between 1 and 32, the size of the hash.
+func Fingerprint(data []byte, nBytes int) string {
+	if nBytes < 1 || nBytes > sha256.Size {
+		panic("padthai: Fingerprint length out of range")
+	}
+	sum := sha256.Sum256(data)
+	return Encode(sum[:nBytes])
+}
@@ -0,0 +1,38 @@
+package padthai
+
+import (
+	"crypto/sha256"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFingerprint(t *testing.T) {
+	data := []byte("Hello, World!")
+	for _, n := range []int{1, 8, 9, 32} {
+		fp := Fingerprint(data, n)
+		if fp != Fingerprint(data, n) {
+			t.Errorf("%d bytes: fingerprint is not deterministic", n)
+		}
+		if got, want := utf8.RuneCountInString(fp), EncodedRuneLen(n); got != want {
+			t.Errorf("%d bytes: %d runes, want %d", n, got, want)
+		}
+		sum := sha256.Sum256(data)
+		if decoded, err := Decode(fp); err != nil || string(decoded) != string(sum[:n]) {
+			t.Errorf("%d bytes: fingerprint decodes to %x, %v", n, decoded, err)
+		}
+	}
+	if Fingerprint(data, 8) == Fingerprint([]byte("Hello, World?"), 8) {
+		t.Errorf("different data, same fingerprint")
+	}
+
+	for _, n := range []int{0, 33} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Fingerprint(data, %d) did not panic", n)
+				}
+			}()
+			Fingerprint(data, n)
+		}()
+	}
+}
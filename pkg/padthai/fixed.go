@@ -0,0 +1,60 @@
+package padthai
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// EncodeFixedWidth encodes data using StdEncoding and right-pads the result
+// with the filler rune fill to exactly width runes, for fixed-width display
+// fields. It returns an error if the encoding is already wider than width, or
+// with ErrAlphabetConflict if fill is a rune of the encoding.
+// DecodeFixedWidth reverses it.
+func EncodeFixedWidth(data []byte, width int, fill rune) (string, error) {
+	return StdEncoding.EncodeFixedWidth(data, width, fill)
+}
+
+// DecodeFixedWidth decodes s, the output of EncodeFixedWidth, using
+// StdEncoding, after stripping the filler runes fill from its end.
+func DecodeFixedWidth(s string, fill rune) ([]byte, error) {
+	return StdEncoding.DecodeFixedWidth(s, fill)
+}
+
+// EncodeFixedWidth is like the package-level EncodeFixedWidth but uses enc.
+func (enc *Encoding) EncodeFixedWidth(data []byte, width int, fill rune) (string, error) {
+	if err := enc.checkFill(fill); err != nil {
+		return "", err
+	}
+	s := enc.Encode(data)
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		return "", fmt.Errorf("padthai: encoding of %d bytes takes %d runes, more than the width of %d", len(data), n, width)
+	}
+	return s + strings.Repeat(string(fill), width-n), nil
+}
+
+// DecodeFixedWidth is like the package-level DecodeFixedWidth but uses enc.
+// Whitespace among the trailing filler runes, such as a final newline, is
+// stripped too.
+func (enc *Encoding) DecodeFixedWidth(s string, fill rune) ([]byte, error) {
+	if err := enc.checkFill(fill); err != nil {
+		return nil, err
+	}
+	return enc.Decode(strings.TrimRightFunc(s, func(r rune) bool {
+		return r == fill || isSpace(r)
+	}))
+}
+
+// checkFill returns an error unless fill can be told apart from the runes
+// of enc's output.
+func (enc *Encoding) checkFill(fill rune) error {
+	_, isDigit := enc.digit(fill)
+	switch {
+	case !utf8.ValidRune(fill):
+		return fmt.Errorf("%w: fill %U is not a valid Unicode scalar value", ErrAlphabetConflict, fill)
+	case isDigit, enc.isPad(fill), strings.ContainsRune(enc.term, fill):
+		return fmt.Errorf("%w: fill %c (%U) is a rune of the encoding", ErrAlphabetConflict, fill, fill)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package padthai
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFixedWidth(t *testing.T) {
+	for _, input := range [][]byte{{}, {0x42}, []byte("Hello"), []byte("Hello, World!")} {
+		s, err := EncodeFixedWidth(input, 24, '·')
+		if err != nil {
+			t.Fatalf("%q: %v", input, err)
+		}
+		if n := utf8.RuneCountInString(s); n != 24 {
+			t.Errorf("%q: %q has %d runes, want 24", input, s, n)
+		}
+		got, err := DecodeFixedWidth(s+"\n", '·')
+		if err != nil {
+			t.Fatalf("%q: decode %q: %v", input, s, err)
+		}
+		if !bytes.Equal(got, input) {
+			t.Errorf("%q: round trip gave %q", input, got)
+		}
+	}
+
+	if _, err := EncodeFixedWidth([]byte("Hello, World!"), 19, '·'); err == nil {
+		t.Error("encoding wider than the width: no error")
+	}
+	for _, fill := range []rune{'ก', 'ᨀ', -1} {
+		if _, err := EncodeFixedWidth(nil, 4, fill); !errors.Is(err, ErrAlphabetConflict) {
+			t.Errorf("fill %U: got %v, want ErrAlphabetConflict", fill, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package padthai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EncodeWithHiddenBits is like Encode but also hides the bits of hidden in
+// the whitespace around the encoding: a space is a 0 bit and a tab a 1 bit,
+// most significant bit first. One bit goes after each rune but the last,
+// and any bits left over follow the last rune.
+//
+// The result decodes to data with Decode as usual, since whitespace is
+// skipped. The hidden channel only survives transports that preserve spaces
+// and tabs exactly; rewrapping or trimming the text destroys it.
+func EncodeWithHiddenBits(data, hidden []byte) string {
+	encoded := Encode(data)
+	var sb strings.Builder
+	sb.Grow(len(encoded) + 8*len(hidden))
+
+	bit := 0 // index of the next bit of hidden
+	writeBit := func() {
+		if hidden[bit/8]&(0x80>>(bit%8)) != 0 {
+			sb.WriteByte('\t')
+		} else {
+			sb.WriteByte(' ')
+		}
+		bit++
+	}
+
+	first := true
+	for _, r := range encoded {
+		if !first && bit < 8*len(hidden) {
+			writeBit()
+		}
+		first = false
+		sb.WriteRune(r)
+	}
+	for bit < 8*len(hidden) {
+		writeBit()
+	}
+	return sb.String()
+}
+
+// DecodeHiddenBits decodes s as produced by EncodeWithHiddenBits, returning
+// both the encoded data and the bytes hidden in its spaces and tabs. Other
+// whitespace carries no bits. It returns an error if s is not a valid
+// encoding or if the hidden bits do not make whole bytes.
+func DecodeHiddenBits(s string) (data, hidden []byte, err error) {
+	data, err = Decode(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hidden = []byte{}
+	bits := 0
+	for _, r := range s {
+		if r != ' ' && r != '\t' {
+			continue
+		}
+		if bits%8 == 0 {
+			hidden = append(hidden, 0)
+		}
+		if r == '\t' {
+			hidden[bits/8] |= 0x80 >> (bits % 8)
+		}
+		bits++
+	}
+	if bits%8 != 0 {
+		return nil, nil, &DecodeError{Kind: InvalidLength, RuneOffset: EncodedRuneLen(len(data)), ByteOffset: len(s), Rune: -1,
+			Err: fmt.Errorf("%w: %d hidden bits do not make whole bytes", ErrInvalidLength, bits)}
+	}
+	return data, hidden, nil
+}
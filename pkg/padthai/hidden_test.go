@@ -0,0 +1,42 @@
+package padthai
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHiddenBitsRoundTrip(t *testing.T) {
+	data := []byte("Hello, World!") // 20 runes, 19 gaps
+	for _, hidden := range [][]byte{{}, {0xA5}, []byte("ok"), []byte("longer than the gaps")} {
+		encoded := EncodeWithHiddenBits(data, hidden)
+
+		// The hidden channel is invisible to Decode
+		decoded, err := Decode(encoded)
+		if err != nil || !bytes.Equal(decoded, data) {
+			t.Errorf("%q: Decode got (%q, %v), want %q", hidden, decoded, err, data)
+		}
+
+		gotData, gotHidden, err := DecodeHiddenBits(encoded)
+		if err != nil {
+			t.Fatalf("%q: DecodeHiddenBits: %v", hidden, err)
+		}
+		if !bytes.Equal(gotData, data) || !bytes.Equal(gotHidden, hidden) {
+			t.Errorf("%q: got (%q, %q)", hidden, gotData, gotHidden)
+		}
+	}
+
+	// Bits go between the runes first
+	if got := EncodeWithHiddenBits([]byte("Hi"), []byte{0x80}); !strings.HasPrefix(got, string(ThaiAlphabet[8])+"\t"+string(ThaiAlphabet[2])+" ") {
+		t.Errorf("got %q, want a tab after the first rune", got)
+	}
+}
+
+func TestDecodeHiddenBitsInvalid(t *testing.T) {
+	if _, _, err := DecodeHiddenBits(Encode([]byte("Hi")) + "   "); err == nil {
+		t.Error("expected error for 3 hidden bits, got nil")
+	}
+	if _, _, err := DecodeHiddenBits("ABC"); err == nil {
+		t.Error("expected error for invalid data, got nil")
+	}
+}
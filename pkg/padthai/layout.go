@@ -0,0 +1,50 @@
+package padthai
+
+import "strings"
+
+// DecodeWithLayout decodes s like Decode and also returns its line layout:
+// for every line break in s, the number of encoding runes that precede it.
+// Passing the layout to ApplyLayout reproduces the same wrapping on a fresh
+// encoding, for instance after the decoded bytes have been edited.
+//
+// Only line breaks ('\n') are recorded; other whitespace is not preserved.
+func DecodeWithLayout(s string) (data []byte, layout []int, err error) {
+	data, err = Decode(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	runes := 0
+	for _, r := range s {
+		if r == '\n' {
+			layout = append(layout, runes)
+			continue
+		}
+		if keep, _ := StdEncoding.accept(r, runes); keep {
+			runes++
+		}
+	}
+	return data, layout, nil
+}
+
+// ApplyLayout inserts a line break into the unwrapped encoding s before each
+// rune position listed in layout, as returned by DecodeWithLayout. Positions
+// must be in ascending order; those beyond the end of s add breaks at the end.
+func ApplyLayout(s string, layout []int) string {
+	var sb strings.Builder
+	sb.Grow(len(s) + len(layout))
+
+	i := 0
+	for _, r := range s {
+		for len(layout) > 0 && layout[0] <= i {
+			sb.WriteByte('\n')
+			layout = layout[1:]
+		}
+		sb.WriteRune(r)
+		i++
+	}
+	for range layout {
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
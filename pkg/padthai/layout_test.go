@@ -0,0 +1,40 @@
+package padthai
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestDecodeWithLayout(t *testing.T) {
+	input := []byte("The quick brown fox jumps over the lazy dog")
+	wrapped := wrapLines(Encode(input), 10) + "\n"
+
+	data, layout, err := DecodeWithLayout(wrapped)
+	if err != nil {
+		t.Fatalf("DecodeWithLayout: %v", err)
+	}
+	if !bytes.Equal(data, input) {
+		t.Fatalf("decoded %q, want %q", data, input)
+	}
+	if want := []int{10, 20, 30, 40, 50, 60, 65}; !slices.Equal(layout, want) {
+		t.Errorf("layout = %v, want %v", layout, want)
+	}
+
+	// Edit the bytes without changing the length, then reapply the wrapping
+	edited := bytes.ToUpper(data)
+	rewrapped := ApplyLayout(Encode(edited), layout)
+	if want := wrapLines(Encode(edited), 10) + "\n"; rewrapped != want {
+		t.Errorf("ApplyLayout:\n got %q\nwant %q", rewrapped, want)
+	}
+}
+
+func TestApplyLayoutEmpty(t *testing.T) {
+	encoded := Encode([]byte{0xCA, 0xFE})
+	if got := ApplyLayout(encoded, nil); got != encoded {
+		t.Errorf("ApplyLayout with no breaks = %q, want %q", got, encoded)
+	}
+	if got := ApplyLayout("", []int{0, 5}); got != "\n\n" {
+		t.Errorf("ApplyLayout of empty string = %q, want two breaks", got)
+	}
+}
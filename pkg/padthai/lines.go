@@ -0,0 +1,87 @@
+package padthai
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// LineError records a failure to decode one line of DecodeLines input.
+type LineError struct {
+	Line int // 1-based line number
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// WordError records a failure to decode one word of DecodeWords input.
+type WordError struct {
+	Word int // 0-based index among the words
+	Err  error
+}
+
+func (e *WordError) Error() string {
+	return fmt.Sprintf("word %d: %v", e.Word, e.Err)
+}
+
+func (e *WordError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeLines reads r line by line and decodes each line as a separate,
+// complete message, as in log formats with one encoding per line. Unlike
+// Decode, which would merge the lines, padding is allowed at the end of
+// every line. Blank lines are skipped and produce no message.
+//
+// Decoding stops at the first malformed line, returning a *LineError; read
+// errors are returned as they are.
+func DecodeLines(r io.Reader) ([][]byte, error) {
+	br := bufio.NewReader(r)
+	messages := [][]byte{}
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if strings.TrimFunc(line, isSpace) != "" {
+			data, derr := Decode(line)
+			if derr != nil {
+				return nil, &LineError{Line: n, Err: derr}
+			}
+			messages = append(messages, data)
+		}
+		if err == io.EOF {
+			return messages, nil
+		}
+	}
+}
+
+// DecodeWords splits s into words at runs of whitespace and decodes each
+// word as a separate, complete message, as when several short tokens are
+// pasted on one line. Unlike Decode, which would merge them, padding is
+// allowed at the end of every word. Soft hyphens do not separate words and
+// are skipped as in Decode.
+//
+// Decoding stops at the first malformed word, returning a *WordError.
+func DecodeWords(s string) ([][]byte, error) {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return r < utf8.RuneSelf && asciiSpace[r]
+	})
+	messages := make([][]byte, 0, len(words))
+	for i, word := range words {
+		data, err := Decode(word)
+		if err != nil {
+			return nil, &WordError{Word: i, Err: err}
+		}
+		messages = append(messages, data)
+	}
+	return messages, nil
+}
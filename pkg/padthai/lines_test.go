@@ -0,0 +1,75 @@
+package padthai
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecodeLines(t *testing.T) {
+	want := []string{"first", "odd", "even"}
+	input := Encode([]byte(want[0])) + "\n\n" + Encode([]byte(want[1])) + "\r\n   \n" + Encode([]byte(want[2]))
+
+	messages, err := DecodeLines(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("DecodeLines: %v", err)
+	}
+	var got []string
+	for _, m := range messages {
+		got = append(got, string(m))
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// A malformed sixth line, after the three messages and two blank lines
+	_, err = DecodeLines(strings.NewReader(input + "\n" + Encode([]byte("bad"))[:9] + "ᨀ\n"))
+	var lerr *LineError
+	if !errors.As(err, &lerr) || lerr.Line != 6 {
+		t.Fatalf("expected a LineError for line 6, got %v", err)
+	}
+	if !errors.Is(err, ErrTruncatedPadding) {
+		t.Errorf("LineError does not wrap the decode error: %v", err)
+	}
+
+	if _, err := DecodeLines(iotest.ErrReader(errSource)); !errors.Is(err, errSource) {
+		t.Errorf("read error: got %v, want %v", err, errSource)
+	}
+}
+
+func TestDecodeWords(t *testing.T) {
+	want := []string{"one", "three", "fiver"}
+	var words []string
+	for _, w := range want {
+		words = append(words, Encode([]byte(w)))
+	}
+
+	messages, err := DecodeWords("  " + strings.Join(words, " \t ") + "\n")
+	if err != nil {
+		t.Fatalf("DecodeWords: %v", err)
+	}
+	var got []string
+	for _, m := range messages {
+		got = append(got, string(m))
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Decode merges the words, and fails on the first word's padding
+	if _, err := Decode(strings.Join(words, " ")); err == nil {
+		t.Errorf("Decode accepted padding between words")
+	}
+
+	_, err = DecodeWords(words[0] + " " + words[1][:12] + " " + words[2])
+	var werr *WordError
+	if !errors.As(err, &werr) || werr.Word != 1 {
+		t.Errorf("malformed second word: got %v", err)
+	}
+
+	if messages, err := DecodeWords(" \n"); err != nil || len(messages) != 0 {
+		t.Errorf("blank input: got %q, %v", messages, err)
+	}
+}
@@ -0,0 +1,27 @@
+package padthai
+
+// looksThreshold is the fraction of non-whitespace runes that must belong to
+// the encoding for Looks to report true.
+const looksThreshold = 0.9
+
+// Looks reports whether s plausibly holds StdEncoding output, for tools that
+// must guess the format of a pasted blob. It is a cheap sniff, not a
+// validation: s looks like padthai if at least 90% of its non-whitespace
+// runes are Thai digits or Buginese padding, and their count is a multiple
+// of 3, possibly plus 2 for padding. Use Decode to know for sure.
+func Looks(s string) bool {
+	n, hits := 0, 0
+	for _, r := range s {
+		if isSpace(r) {
+			continue
+		}
+		n++
+		if isThai(r) || isBuginese(r) {
+			hits++
+		}
+	}
+	if n == 0 || n%3 == 1 {
+		return false
+	}
+	return float64(hits) >= looksThreshold*float64(n)
+}
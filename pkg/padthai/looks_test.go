@@ -0,0 +1,29 @@
+package padthai
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestLooks(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog")
+	tests := []struct {
+		name string
+		s    string
+		want bool
+	}{
+		{"padthai", Encode(data), true},
+		{"wrapped padthai", wrapLines(Encode(data), 10), true},
+		{"one typo", Encode(data)[:3] + "x" + Encode(data)[6:], true},
+		{"truncated padthai", Encode(data)[:12], false},
+		{"english", string(data), false},
+		{"base64", base64.StdEncoding.EncodeToString(data), false},
+		{"empty", "", false},
+		{"whitespace", " \n\t", false},
+	}
+	for _, tt := range tests {
+		if got := Looks(tt.s); got != tt.want {
+			t.Errorf("%s: Looks = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package padthai
+
+import (
+	"io"
+	"os"
+)
+
+// mmapWindow is the amount of mapped input EncodeMmap hands to the encoder at
+// a time. It is even, so that no byte is carried from one window to the next.
+const mmapWindow = 1 << 20
+
+// EncodeMmap encodes the file at path and writes the encoding to dst. On Unix
+// systems the file is memory-mapped and encoded in large windows straight
+// from the mapping, which avoids copying multi-gigabyte inputs through read
+// buffers; elsewhere, or if the file cannot be mapped, it is streamed as with
+// EncodeReaderTo.
+//
+// The file must not be truncated while it is being encoded: accessing a
+// mapped page past the new end of the file crashes the program.
+func EncodeMmap(path string, dst io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return encodeFile(f, dst)
+}
+
+// encodeStream encodes f by reading it, for files that cannot be mapped.
+func encodeStream(f *os.File, dst io.Writer) error {
+	_, err := EncodeReaderTo(dst, f)
+	return err
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package padthai
+
+import (
+	"io"
+	"os"
+)
+
+// encodeFile encodes f by reading it: memory mapping is only used on Unix.
+func encodeFile(f *os.File, dst io.Writer) error {
+	return encodeStream(f, dst)
+}
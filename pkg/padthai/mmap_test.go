@@ -0,0 +1,51 @@
+package padthai
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncodeMmap(t *testing.T) {
+	dir := t.TempDir()
+	for _, size := range []int{0, 1, 3<<20 + 1} {
+		input := make([]byte, size)
+		_, _ = io.ReadFull(rand.Reader, input)
+		path := filepath.Join(dir, "input")
+		if err := os.WriteFile(path, input, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		var mapped, streamed bytes.Buffer
+		if err := EncodeMmap(path, &mapped); err != nil {
+			t.Fatalf("size %d: EncodeMmap: %v", size, err)
+		}
+		if _, err := EncodeReaderTo(&streamed, bytes.NewReader(input)); err != nil {
+			t.Fatalf("size %d: EncodeReaderTo: %v", size, err)
+		}
+		if !bytes.Equal(mapped.Bytes(), streamed.Bytes()) {
+			t.Errorf("size %d: mapped output differs from the streaming path", size)
+		}
+	}
+
+	if err := EncodeMmap(filepath.Join(dir, "missing"), io.Discard); !os.IsNotExist(err) {
+		t.Errorf("missing file: got %v, want a not-exist error", err)
+	}
+
+	// A file claiming a size of 0 is still read to its end
+	const proc = "/proc/self/cmdline"
+	want, err := os.ReadFile(proc)
+	if err != nil || len(want) == 0 {
+		return // no procfs here
+	}
+	var out bytes.Buffer
+	if err := EncodeMmap(proc, &out); err != nil {
+		t.Fatalf("%s: EncodeMmap: %v", proc, err)
+	}
+	if got, err := Decode(out.String()); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("%s: encoded %d bytes, want %d (%v)", proc, len(got), len(want), err)
+	}
+}
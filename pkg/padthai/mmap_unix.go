@@ -0,0 +1,40 @@
+//go:build unix
+
+package padthai
+
+import (
+	"io"
+	"math"
+	"os"
+	"syscall"
+)
+
+// encodeFile encodes f from a read-only memory mapping.
+func encodeFile(f *os.File, dst io.Writer) error {
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	// Files such as those in /proc report a size of 0 whatever they hold,
+	// and there is nothing to map in an empty file anyway
+	size := fi.Size()
+	if size == 0 || !fi.Mode().IsRegular() || size > math.MaxInt {
+		return encodeStream(f, dst)
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return encodeStream(f, dst)
+	}
+	defer syscall.Munmap(data)
+
+	enc := NewEncoder(dst)
+	for len(data) > 0 {
+		n := min(len(data), mmapWindow)
+		if _, err := enc.Write(data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return enc.Close()
+}
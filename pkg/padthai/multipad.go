@@ -0,0 +1,50 @@
+package padthai
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DecodeMultiPadded salvages the concatenation of several encodings, as
+// produced by joining the output of Encode for odd-length messages. Padding
+// marks the end of an encoding, so the input is split after each pair of
+// Buginese runes and every segment is decoded independently with Decode.
+//
+// The split is ambiguous: nothing marks the end of an even-length message, so
+// it is merged with the message that follows it, and the result has fewer
+// segments than were concatenated. Decoding fails, reporting the segment, if
+// any segment is invalid on its own, such as one that ends in a single
+// padding rune.
+func DecodeMultiPadded(s string) ([][]byte, error) {
+	var segments [][]byte
+	start := 0
+	pads := 0 // consecutive padding runes just before i
+	for i, r := range s {
+		if isSpace(r) {
+			continue
+		}
+		if !isBuginese(r) {
+			pads = 0
+			continue
+		}
+		if pads++; pads == 2 {
+			end := i + utf8.RuneLen(r)
+			data, err := Decode(s[start:end])
+			if err != nil {
+				return nil, fmt.Errorf("padthai: segment %d: %w", len(segments), err)
+			}
+			segments = append(segments, data)
+			start, pads = end, 0
+		}
+	}
+
+	if rest := s[start:]; strings.TrimFunc(rest, isSpace) != "" {
+		data, err := Decode(rest)
+		if err != nil {
+			return nil, fmt.Errorf("padthai: segment %d: %w", len(segments), err)
+		}
+		segments = append(segments, data)
+	}
+	return segments, nil
+}
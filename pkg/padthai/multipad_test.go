@@ -0,0 +1,39 @@
+package padthai
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecodeMultiPadded(t *testing.T) {
+	first, second := []byte("odd"), []byte("also odd")
+	joined := Encode(first) + "\n" + Encode(second) + "\n"
+
+	if _, err := Decode(joined); err == nil {
+		t.Fatal("Decode accepted padding in the middle")
+	}
+
+	segments, err := DecodeMultiPadded(joined)
+	if err != nil {
+		t.Fatalf("DecodeMultiPadded: %v", err)
+	}
+	if len(segments) != 2 || !bytes.Equal(segments[0], first) || !bytes.Equal(segments[1], second) {
+		t.Errorf("got %q, want [%q %q]", segments, first, second)
+	}
+
+	// An even-length message merges with the next one
+	segments, err = DecodeMultiPadded(Encode([]byte("ev")) + Encode(first) + Encode([]byte("tail")))
+	if err != nil {
+		t.Fatalf("DecodeMultiPadded: %v", err)
+	}
+	if len(segments) != 2 || string(segments[0]) != "evodd" || string(segments[1]) != "tail" {
+		t.Errorf("got %q, want [evodd tail]", segments)
+	}
+
+	if _, err := DecodeMultiPadded(Encode(first)[:12] + Encode(second)); err == nil {
+		t.Error("expected error for an invalid segment, got nil")
+	}
+	if segments, err := DecodeMultiPadded(" \n"); err != nil || len(segments) != 0 {
+		t.Errorf("blank input: got (%q, %v), want no segments", segments, err)
+	}
+}
@@ -0,0 +1,97 @@
+package padthai
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+)
+
+// Unsigned is the set of integer types EncodeNumber and DecodeNumber accept.
+type Unsigned interface {
+	~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// numberWidth returns the width in bytes of T.
+func numberWidth[T Unsigned]() int {
+	return bits.Len64(uint64(^T(0))) / 8
+}
+
+// EncodeNumber encodes v as a big-endian integer of its natural width: 1, 2,
+// 4 or 8 bytes, whatever its value.
+func EncodeNumber[T Unsigned](v T) string {
+	var buf [8]byte
+	n := numberWidth[T]()
+	for i := 0; i < n; i++ {
+		buf[n-1-i] = byte(uint64(v) >> (8 * i))
+	}
+	return Encode(buf[:n])
+}
+
+// DecodeNumber decodes s, as encoded by EncodeNumber, into a T. It returns an
+// error if s does not decode to exactly the width of T.
+func DecodeNumber[T Unsigned](s string) (T, error) {
+	data, err := Decode(s)
+	if err != nil {
+		return 0, err
+	}
+	n := numberWidth[T]()
+	if len(data) != n {
+		return 0, &DecodeError{Kind: InvalidLength, RuneOffset: EncodedRuneLen(len(data)), ByteOffset: len(s), Rune: -1,
+			Err: fmt.Errorf("%w: decoded %d bytes, want %d for a %d-bit number", ErrInvalidLength, len(data), n, 8*n)}
+	}
+	var v uint64
+	for _, b := range data {
+		v = v<<8 | uint64(b)
+	}
+	return T(v), nil
+}
+
+// bigDigits are the digits math/big uses for base 48, in order.
+const bigDigits = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKL"
+
+// EncodeDenseInt encodes the non-negative integer v as a single base-48
+// numeral of Thai digits, most significant first. Unlike Encode there are no
+// triplets and no padding: every digit carries information, so the result is
+// as short as possible, about 1.43 runes per byte of v instead of 1.5, and
+// any string of Thai digits is a valid numeral.
+//
+// Zero encodes as the single digit ก, and other values have no leading
+// zero digits. EncodeDenseInt panics if v is negative.
+func EncodeDenseInt(v *big.Int) string {
+	if v.Sign() < 0 {
+		panic("padthai: EncodeDenseInt of a negative number")
+	}
+	text := v.Text(Base)
+	var sb strings.Builder
+	sb.Grow(len(text) * 3)
+	for i := 0; i < len(text); i++ {
+		sb.WriteRune(ThaiAlphabet[strings.IndexByte(bigDigits, text[i])])
+	}
+	return sb.String()
+}
+
+// DecodeDenseInt decodes s, as encoded by EncodeDenseInt, into a new
+// big.Int. Whitespace is skipped, as in Decode. Leading zero digits are
+// accepted and do not change the value, so the encoding of a number is only
+// unique if they are left out.
+func DecodeDenseInt(s string) (*big.Int, error) {
+	text := make([]byte, 0, len(s)/3)
+	for i, r := range s {
+		if isSpace(r) {
+			continue
+		}
+		d, ok := thaiIndex[r]
+		if !ok {
+			return nil, &DecodeError{Kind: InvalidChar, RuneOffset: len(text), ByteOffset: i, Rune: r,
+				Err: fmt.Errorf("%w %U at position %d", ErrInvalidCharacter, r, len(text))}
+		}
+		text = append(text, bigDigits[d])
+	}
+	if len(text) == 0 {
+		return nil, &DecodeError{Kind: InvalidLength, ByteOffset: len(s), Rune: -1,
+			Err: fmt.Errorf("%w: dense integer has no digits", ErrInvalidLength)}
+	}
+	v, _ := new(big.Int).SetString(string(text), Base)
+	return v, nil
+}
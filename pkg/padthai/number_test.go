@@ -0,0 +1,92 @@
+package padthai
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func testNumberRoundTrip[T Unsigned](t *testing.T, values ...T) {
+	t.Helper()
+	for _, v := range values {
+		encoded := EncodeNumber(v)
+		if got, want := len([]rune(encoded)), EncodedRuneLen(numberWidth[T]()); got != want {
+			t.Errorf("%T(%d): expected %d runes, got %d", v, v, want, got)
+		}
+		decoded, err := DecodeNumber[T](encoded)
+		if err != nil {
+			t.Fatalf("%T(%d): decode: %v", v, v, err)
+		}
+		if decoded != v {
+			t.Errorf("%T(%d): roundtrip mismatch: got %d", v, v, decoded)
+		}
+	}
+}
+
+type port uint16
+
+func TestNumberRoundTrip(t *testing.T) {
+	testNumberRoundTrip[uint8](t, 0, 1, 0x7F, math.MaxUint8)
+	testNumberRoundTrip[uint16](t, 0, 1, 0x1234, math.MaxUint16)
+	testNumberRoundTrip[uint32](t, 0, 1, 0x12345678, math.MaxUint32)
+	testNumberRoundTrip[uint64](t, 0, 1, 0x123456789ABCDEF0, math.MaxUint64)
+	testNumberRoundTrip[port](t, 8080)
+}
+
+func TestNumberBigEndian(t *testing.T) {
+	if got, want := EncodeNumber(uint16(0x4869)), Encode([]byte("Hi")); got != want {
+		t.Errorf("EncodeNumber(0x4869) = %q, want %q", got, want)
+	}
+}
+
+func TestDecodeNumberWidth(t *testing.T) {
+	if _, err := DecodeNumber[uint32](EncodeNumber(uint16(1))); err == nil {
+		t.Error("expected error decoding a uint16 as uint32, got nil")
+	}
+	if _, err := DecodeNumber[uint8](EncodeNumber(uint64(1))); err == nil {
+		t.Error("expected error decoding a uint64 as uint8, got nil")
+	}
+	if _, err := DecodeNumber[uint16]("ABC"); err == nil {
+		t.Error("expected error for invalid input, got nil")
+	}
+}
+
+func TestDenseIntRoundTrip(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890123456789012345678901234567890", 10)
+	for _, v := range []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(Base - 1),
+		big.NewInt(Base),
+		new(big.Int).SetUint64(math.MaxUint64),
+		huge,
+	} {
+		encoded := EncodeDenseInt(v)
+		decoded, err := DecodeDenseInt(encoded)
+		if err != nil {
+			t.Fatalf("%v: decode: %v", v, err)
+		}
+		if decoded.Cmp(v) != 0 {
+			t.Errorf("%v: roundtrip mismatch: got %v", v, decoded)
+		}
+	}
+
+	if got, want := EncodeDenseInt(big.NewInt(Base+2)), string([]rune{ThaiAlphabet[1], ThaiAlphabet[2]}); got != want {
+		t.Errorf("EncodeDenseInt(50) = %q, want %q", got, want)
+	}
+	if got := EncodeDenseInt(new(big.Int)); got != string(ThaiAlphabet[0]) {
+		t.Errorf("EncodeDenseInt(0) = %q", got)
+	}
+
+	// Leading zeros and whitespace do not change the value
+	v, err := DecodeDenseInt(string(ThaiAlphabet[0]) + " " + EncodeDenseInt(big.NewInt(12345)))
+	if err != nil || v.Int64() != 12345 {
+		t.Errorf("leading zero: got %v, %v", v, err)
+	}
+
+	for _, s := range []string{"", " ", string(BugineseAlphabet[0])} {
+		if _, err := DecodeDenseInt(s); err == nil {
+			t.Errorf("DecodeDenseInt(%q) succeeded", s)
+		}
+	}
+}
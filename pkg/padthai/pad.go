@@ -0,0 +1,142 @@
+package padthai
+
+import (
+	"fmt"
+	"slices"
+)
+
+// PadEncoding is a scheme for encoding a trailing odd byte, which does not
+// fit the main groups of digits.
+//
+// Padding runes must be told apart from digits, so IsPad must report false
+// for every rune of the main alphabet. Encode must return between 1 and 8
+// runes, all of them padding, so that decoders can find the padding by
+// scanning back from the end of the input.
+type PadEncoding interface {
+	// Encode returns the padding runes for b.
+	Encode(b byte) []rune
+
+	// Decode returns the byte encoded by runes, the trailing padding of an
+	// input, and false if runes are not a valid encoding.
+	Decode(runes []rune) (byte, bool)
+
+	// IsPad reports whether r is a padding rune.
+	IsPad(r rune) bool
+}
+
+// NibblePad is the default PadEncoding: a byte becomes two runes, the first
+// for its high nibble and the second for its low nibble.
+type NibblePad [PadBase]rune
+
+// BuginesePad is the padding scheme of StdEncoding, using the 16 Buginese
+// characters of BugineseAlphabet.
+var BuginesePad PadEncoding
+
+// Encode returns the runes for the high and low nibbles of b.
+func (p NibblePad) Encode(b byte) []rune {
+	return []rune{p[b>>4], p[b&0x0f]}
+}
+
+// Decode returns the byte whose nibbles are the two runes.
+func (p NibblePad) Decode(runes []rune) (byte, bool) {
+	if len(runes) != 2 {
+		return 0, false
+	}
+	hi := slices.Index(p[:], runes[0])
+	lo := slices.Index(p[:], runes[1])
+	if hi < 0 || lo < 0 {
+		return 0, false
+	}
+	return byte(hi<<4 | lo), true
+}
+
+// IsPad reports whether r is one of the 16 nibble runes.
+func (p NibblePad) IsPad(r rune) bool {
+	return slices.Contains(p[:], r)
+}
+
+// WithPadEncoding returns a new encoding identical to enc except that a
+// trailing odd byte is encoded with p instead of the padding alphabet. The
+// pad encoding is a tail scheme like WithMixedTail and WithZeroPadTrailer:
+// the last one applied wins.
+//
+// A NibblePad replaces the padding alphabet itself, so that it is encoded and
+// decoded exactly as the padding of NewEncoding: StdEncoding is
+// StdEncoding.WithPadEncoding(BuginesePad). Other schemes are called through
+// the interface.
+//
+// It returns an error wrapping ErrAlphabetConflict if p claims a rune of the
+// main alphabet or of the terminator as padding.
+func (enc Encoding) WithPadEncoding(p PadEncoding) (*Encoding, error) {
+	for r := range enc.index {
+		if p.IsPad(r) {
+			return nil, fmt.Errorf("%w: padding conflicts with digit %U", ErrAlphabetConflict, r)
+		}
+	}
+	for _, r := range enc.term {
+		if p.IsPad(r) {
+			return nil, fmt.Errorf("%w: padding conflicts with terminator %U", ErrAlphabetConflict, r)
+		}
+	}
+	enc.tail = tailPadded
+	enc.padEnc = nil
+	if nibbles, ok := p.(NibblePad); ok {
+		if err := enc.setNibblePad(nibbles); err != nil {
+			return nil, err
+		}
+		return &enc, nil
+	}
+	enc.padEnc = p
+	return &enc, nil
+}
+
+// setNibblePad makes p the padding alphabet of enc, whose main alphabet must
+// already be indexed. It replaces rather than updates padIndex, which enc may
+// share with the encoding it was copied from.
+func (enc *Encoding) setNibblePad(p NibblePad) error {
+	padIndex := make(map[rune]int, PadBase)
+	for i, r := range p {
+		if err := checkAlphabetRune(r); err != nil {
+			return err
+		}
+		_, inMain := enc.index[r]
+		_, dup := padIndex[r]
+		if inMain || dup {
+			return fmt.Errorf("%w: %U appears more than once", ErrAlphabetConflict, r)
+		}
+		padIndex[r] = i
+	}
+	enc.pad = p
+	enc.padIndex = padIndex
+	for i, r := range p {
+		enc.padStr[i] = string(r)
+	}
+	return nil
+}
+
+// decodeCustomPad is decodeRunes for encodings with a PadEncoding.
+func (enc *Encoding) decodeCustomPad(dst []byte, runes []rune, pos int) ([]byte, error) {
+	n := len(runes)
+	for n > 0 && enc.padEnc.IsPad(runes[n-1]) {
+		n--
+	}
+	if n%3 != 0 {
+		return nil, corrupt(InvalidLength, pos+n, -1, fmt.Errorf("%w: %d Thai characters is not a multiple of 3", ErrInvalidLength, pos+n))
+	}
+
+	dst, err := enc.decodeGroups(dst, runes[:n], pos)
+	if err != nil {
+		return nil, err
+	}
+
+	if n < len(runes) {
+		// Pass a copy: handing runes to an interface method would make them
+		// escape, forcing every decoder's rune buffer onto the heap
+		b, ok := enc.padEnc.Decode(slices.Clone(runes[n:]))
+		if !ok {
+			return nil, corrupt(BadPadding, pos+n, runes[n], fmt.Errorf("%w %q at position %d", ErrInvalidPadding, string(runes[n:]), pos+n))
+		}
+		dst = append(dst, b)
+	}
+	return dst, nil
+}
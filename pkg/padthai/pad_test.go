@@ -0,0 +1,131 @@
+package padthai
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// hexPad encodes a trailing byte as '=' and two uppercase hex digits.
+type hexPad struct{}
+
+func (hexPad) Encode(b byte) []rune {
+	return []rune(fmt.Sprintf("=%02X", b))
+}
+
+func (hexPad) Decode(runes []rune) (byte, bool) {
+	if len(runes) != 3 || runes[0] != '=' {
+		return 0, false
+	}
+	b, err := strconv.ParseUint(string(runes[1:]), 16, 8)
+	return byte(b), err == nil
+}
+
+func (hexPad) IsPad(r rune) bool {
+	return r == '=' || (r >= '0' && r <= '9') || (r >= 'A' && r <= 'F')
+}
+
+// withHexPad returns StdEncoding with hexPad as its padding.
+func withHexPad(t *testing.T) *Encoding {
+	t.Helper()
+	enc, err := StdEncoding.WithPadEncoding(hexPad{})
+	if err != nil {
+		t.Fatalf("WithPadEncoding: %v", err)
+	}
+	return enc
+}
+
+func TestPadEncoding(t *testing.T) {
+	enc := withHexPad(t)
+
+	input := []byte("Hello, World!")
+	encoded := enc.Encode(input)
+	if !strings.HasSuffix(encoded, "=21") {
+		t.Errorf("encoding %q does not end in hex padding", encoded)
+	}
+	if got, want := encoded, Encode(input[:12])+"=21"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	for _, size := range []int{0, 1, 2, 3, 13} {
+		decoded, err := enc.Decode(enc.Encode(input[:size]))
+		if err != nil {
+			t.Fatalf("size %d: decode: %v", size, err)
+		}
+		if !bytes.Equal(decoded, input[:size]) {
+			t.Errorf("size %d: roundtrip mismatch: got %q", size, decoded)
+		}
+	}
+
+	// The streaming decoder finds the padding the same way
+	decoded, err := io.ReadAll(enc.NewDecoder(strings.NewReader(encoded)))
+	if err != nil || !bytes.Equal(decoded, input) {
+		t.Errorf("streaming: got (%q, %v), want %q", decoded, err, input)
+	}
+
+	for _, bad := range []string{Encode(input[:12]) + "=2", Encode(input[:12]) + "21", Encode(input[:11])} {
+		if _, err := enc.Decode(bad); err == nil {
+			t.Errorf("Decode(%q): expected error, got nil", bad)
+		}
+	}
+}
+
+func TestBuginesePad(t *testing.T) {
+	enc, err := CompactEncoding.WithPadEncoding(BuginesePad)
+	if err != nil {
+		t.Fatalf("WithPadEncoding: %v", err)
+	}
+	// A NibblePad takes the place of the padding alphabet rather than
+	// being called through the interface
+	if enc.padEnc != nil {
+		t.Error("NibblePad set as a custom PadEncoding")
+	}
+	std, err := NewEncoding(CompactEncoding.alphabet, BugineseAlphabet[:])
+	if err != nil {
+		t.Fatalf("NewEncoding: %v", err)
+	}
+	for _, input := range [][]byte{{0x00}, {0xAB}, []byte("odd")} {
+		encoded := enc.Encode(input)
+		if want := std.Encode(input); encoded != want {
+			t.Errorf("%x: got %q, want %q", input, encoded, want)
+		}
+		decoded, err := enc.Decode(encoded)
+		if err != nil || !bytes.Equal(decoded, input) {
+			t.Errorf("%x: decode got (%x, %v)", input, decoded, err)
+		}
+	}
+	// CompactEncoding keeps its own padding
+	if got, want := CompactEncoding.Encode([]byte{0xAB}), std.Encode([]byte{0xAB}); got == want {
+		t.Errorf("CompactEncoding padding changed to %q", got)
+	}
+}
+
+func TestPadEncodingConflict(t *testing.T) {
+	for name, p := range map[string]PadEncoding{
+		"digit":     NibblePad(ThaiAlphabet[:PadBase]),
+		"duplicate": NibblePad(slices.Repeat([]rune{0x1A00}, PadBase)),
+	} {
+		if _, err := StdEncoding.WithPadEncoding(p); !errors.Is(err, ErrAlphabetConflict) {
+			t.Errorf("%s: got %v, want ErrAlphabetConflict", name, err)
+		}
+	}
+	// A baht sign read by NoBahtEncoding is a digit too
+	if _, err := NoBahtEncoding.WithPadEncoding(runePad(0x0E3F)); !errors.Is(err, ErrAlphabetConflict) {
+		t.Errorf("baht sign: got %v, want ErrAlphabetConflict", err)
+	}
+	if _, err := StdEncoding.WithExplicitTerminator().WithPadEncoding(runePad(0x1A1E)); !errors.Is(err, ErrAlphabetConflict) {
+		t.Errorf("terminator: got %v, want ErrAlphabetConflict", err)
+	}
+}
+
+// runePad claims a single rune as padding.
+type runePad rune
+
+func (p runePad) Encode(b byte) []rune             { return []rune{rune(p)} }
+func (p runePad) Decode(runes []rune) (byte, bool) { return 0, false }
+func (p runePad) IsPad(r rune) bool                { return r == rune(p) }
@@ -9,8 +9,16 @@
 package padthai
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/bits"
+	"slices"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -20,18 +28,48 @@ const (
 	thaiEnd   = '\u0e2f'
 	thaiBaht  = '\u0e3f'
 
+	// Thai digit zero, standing in for the baht sign in NoBahtEncoding
+	thaiDigitZero = '\u0e50'
+
 	// Buginese character range: U+1A00 to U+1A0F (16 chars) for padding
 	bugineseStart = '\u1a00'
 	bugineseEnd   = '\u1a0f'
 
+	// Cyrillic capital and small letters for CompactEncoding: U+0410 to
+	// U+043F (48 chars) as digits, U+0440 to U+044F (16 chars) as padding
+	cyrillicStart    = '\u0410'
+	cyrillicPadStart = '\u0440'
+
+	// Buginese pallawa and end of section, used as an explicit terminator
+	bugineseTerminator = "\u1a1e\u1a1f"
+
 	// Base is the radix for the main encoding (48 Thai characters).
 	Base = 48
 
 	// PadBase is the number of Buginese characters used for padding.
 	PadBase = 16
+
+	// MaxTripletValue is the largest value a canonical triplet of Base
+	// digits may hold: the 16 bits of one byte pair.
+	MaxTripletValue = 0xFFFF
+
+	// TripletCombinations is the number of distinct triplets of Base digits,
+	// canonical or not (48³ = 110,592).
+	TripletCombinations = Base * Base * Base
+
+	// minBase is the smallest radix for which 3 digits can hold 16 bits
+	// (41³ = 68,921 ≥ 2¹⁶ > 40³).
+	minBase = 41
 )
 
 // ThaiAlphabet is the ordered set of 48 Thai characters used for encoding.
+//
+// It holds consonants, the letters up to PAIYANNOI and the baht sign, but no
+// vowel signs or tone marks, so the input method quirks around SARA AM do not
+// touch it: whether SARA AM (U+0E33) is typed as such or as NIKHAHIT (U+0E4D)
+// and SARA AA (U+0E32), with a tone mark before or after the NIKHAHIT, none
+// of these runes is a digit, and Decode rejects them rather than reading them
+// as different data. No normalization is needed before indexing.
 var ThaiAlphabet [Base]rune
 
 // BugineseAlphabet is the ordered set of 16 Buginese characters used for padding.
@@ -40,6 +78,120 @@ var BugineseAlphabet [PadBase]rune
 // thaiIndex maps a Thai rune to its index in the alphabet (0–47).
 var thaiIndex map[rune]int
 
+// ErrVariationSelector is returned when the input to a strict decoder
+// contains a Unicode variation selector (U+FE00–U+FE0F, U+E0100–U+E01EF).
+// Such selectors are typically inserted by emoji keyboards and input methods.
+var ErrVariationSelector = errors.New("padthai: unexpected variation selector")
+
+// ErrAlphabetSize is returned by NewEncoding when an alphabet has the wrong
+// number of runes.
+var ErrAlphabetSize = errors.New("padthai: invalid alphabet size")
+
+// ErrAlphabetConflict is returned by NewEncoding when an alphabet contains a
+// duplicate rune, or a rune the decoder could not tell apart from input noise.
+var ErrAlphabetConflict = errors.New("padthai: alphabet conflict")
+
+// ErrTruncatedPadding is returned when the input ends with a single padding
+// character. Padding always comes in pairs, so the input was most likely cut
+// short by one character.
+var ErrTruncatedPadding = errors.New("padthai: truncated padding")
+
+// ErrInvalidPadding is returned when padding characters appear anywhere
+// but in a single pair at the end of the input.
+var ErrInvalidPadding = errors.New("padthai: invalid padding")
+
+// ErrMissingTerminator is returned when decoding with an encoding that
+// requires an explicit terminator and the input does not end with one.
+var ErrMissingTerminator = errors.New("padthai: missing terminator")
+
+// ErrInvalidUTF8 is returned when the input to Decode is not valid UTF-8.
+// The error reports the byte offset of the first invalid byte.
+var ErrInvalidUTF8 = errors.New("padthai: invalid UTF-8")
+
+// ErrNonCanonical is returned in two cases, told apart by the Kind of the
+// DecodeError wrapping it:
+//
+//   - OutOfRange: a group of valid digits decodes to a value too large for
+//     its bytes, such as a triplet above 0xFFFF. Encode never produces such
+//     a group, so one of its digits is corrupt.
+//   - NotCanonical: DecodeCanonical was given input that decodes, but is not
+//     the string Encode produces for its data, such as wrapped or
+//     whitespace-padded text. The input is well-formed, not corrupt.
+var ErrNonCanonical = errors.New("padthai: non-canonical digits")
+
+// ErrInvalidLength is returned when the input has a number of runes that no
+// encoding has, such as a number of digits that is not a multiple of 3.
+var ErrInvalidLength = errors.New("padthai: invalid encoded length")
+
+// ErrInvalidCharacter is returned when the input contains a rune that is
+// neither skipped nor part of the encoding where it appears.
+var ErrInvalidCharacter = errors.New("padthai: invalid character")
+
+// ErrNoContent is returned by DecodeStrict when the input is not empty but
+// consists only of whitespace.
+var ErrNoContent = errors.New("padthai: input has no content")
+
+// Encoding is a padthai encoding defined by its main and padding alphabets.
+//
+// An Encoding is immutable once constructed: methods that configure it, such
+// as Lenient, return a modified copy and leave the receiver untouched. It is
+// therefore safe for concurrent use by multiple goroutines, including
+// StdEncoding. Any state added to Encoding in the future, such as caches or
+// pools, must keep that guarantee by synchronizing internally.
+//
+// The zero value is not usable; use StdEncoding or NewEncoding.
+type Encoding struct {
+	alphabet []rune
+	base     uint
+	pad      [PadBase]rune
+	digitStr []string        // UTF-8 encoding of each alphabet rune
+	digitLen int             // fewest UTF-8 bytes of any alphabet rune
+	padStr   [PadBase]string // UTF-8 encoding of each padding rune
+	index    map[rune]int    // main alphabet rune -> digit
+	padIndex map[rune]int    // padding alphabet rune -> nibble
+	group    int             // input bytes per main group: 2, or 4 for word groups
+	skip     func(rune) bool
+	fold     func(rune) rune // maps kept input runes before lookup, if set
+	term     string          // explicit terminator appended to every encoding, if any
+	tail     tailScheme
+	padEnc   PadEncoding // replaces pad and padIndex if set
+	reverse  bool        // digits least significant first
+	lenient  bool
+}
+
+// tailScheme selects how an Encoding represents a trailing odd byte.
+type tailScheme int
+
+const (
+	tailPadded tailScheme = iota // 2 padding runes, one per nibble
+	tailMixed                    // 1 digit and 1 padding rune
+	tailZero                     // a zero byte completing the pair, then a marker digit
+	tailThai                     // 2 digits
+)
+
+// StdEncoding is the standard padthai encoding, using the 48 Thai characters
+// as digits and the 16 Buginese characters as padding.
+//
+// StdEncoding is strict: decoding rejects variation selectors with
+// ErrVariationSelector. Use StdEncoding.Lenient() to strip them instead.
+var StdEncoding *Encoding
+
+// CompactEncoding is an alternative to StdEncoding using the same algorithm
+// with Cyrillic letters, U+0410–U+043F as digits and U+0440–U+044F as
+// padding. Each of these takes 2 bytes in UTF-8 rather than 3, so the output
+// is a third smaller: about 3 bytes per input byte instead of 4.5.
+var CompactEncoding *Encoding
+
+// NoBahtEncoding is StdEncoding with the Thai digit zero ๐ (U+0E50) as its
+// last digit in place of the baht sign ฿ (U+0E3F), which some monospace
+// terminals render at the wrong width. All 46 Thai consonants are already
+// digits, so the stand-in is a Thai digit: like them, it is a spacing
+// character that never combines with its neighbours.
+//
+// It never emits the baht sign, but decodes it as the last digit too, so it
+// reads StdEncoding output as well as its own.
+var NoBahtEncoding *Encoding
+
 func init() {
 	idx := 0
 	for r := thaiStart; r <= thaiEnd; r++ {
@@ -57,6 +209,327 @@ func init() {
 	for i := 0; i < PadBase; i++ {
 		BugineseAlphabet[i] = bugineseStart + rune(i)
 	}
+
+	bugPad := NibblePad(BugineseAlphabet)
+	BuginesePad = bugPad
+
+	// StdEncoding pads with BuginesePad, through the same code as any
+	// NibblePad given to NewEncoding or WithPadEncoding
+	StdEncoding = &Encoding{
+		alphabet: ThaiAlphabet[:],
+		base:     Base,
+		index:    thaiIndex,
+		group:    2,
+	}
+	if err := StdEncoding.setNibblePad(bugPad); err != nil {
+		panic(err)
+	}
+	StdEncoding.encodeStrings()
+
+	var main [Base]rune
+	var pad [PadBase]rune
+	for i := range main {
+		main[i] = cyrillicStart + rune(i)
+	}
+	for i := range pad {
+		pad[i] = cyrillicPadStart + rune(i)
+	}
+	var err error
+	if CompactEncoding, err = NewEncoding(main[:], pad[:]); err != nil {
+		panic(err)
+	}
+
+	noBaht := ThaiAlphabet
+	noBaht[Base-1] = thaiDigitZero
+	if NoBahtEncoding, err = NewEncoding(noBaht[:], BugineseAlphabet[:]); err != nil {
+		panic(err)
+	}
+	NoBahtEncoding.index[thaiBaht] = Base - 1
+
+	// Register here rather than in an init of registry.go, which would
+	// depend on the order the files are compiled in
+	Register("thai", StdEncoding)
+	Register("compact", CompactEncoding)
+	Register("nobaht", NoBahtEncoding)
+}
+
+// NewEncoding returns a new Encoding using main as the digit alphabet and pad
+// as the 16-rune padding alphabet for a trailing odd byte.
+//
+// The main alphabet must have at least 41 runes, so that every 16-bit value
+// fits in 3 digits; its length is the radix of the encoding. Every rune must be
+// a valid Unicode scalar value, distinct from all others in either alphabet,
+// and neither whitespace, a soft hyphen, a control character nor a variation
+// selector, since the decoder skips or rejects those.
+func NewEncoding(main, pad []rune) (*Encoding, error) {
+	if len(main) < minBase {
+		return nil, fmt.Errorf("%w: main alphabet has %d runes, need at least %d", ErrAlphabetSize, len(main), minBase)
+	}
+	if len(pad) != PadBase {
+		return nil, fmt.Errorf("%w: padding alphabet has %d runes, need %d", ErrAlphabetSize, len(pad), PadBase)
+	}
+
+	enc := &Encoding{
+		alphabet: append([]rune(nil), main...),
+		base:     uint(len(main)),
+		index:    make(map[rune]int, len(main)),
+		group:    2,
+	}
+	for i, r := range main {
+		if err := checkAlphabetRune(r); err != nil {
+			return nil, err
+		}
+		if _, dup := enc.index[r]; dup {
+			return nil, fmt.Errorf("%w: %U appears more than once", ErrAlphabetConflict, r)
+		}
+		enc.index[r] = i
+	}
+	if err := enc.setNibblePad(NibblePad(pad)); err != nil {
+		return nil, err
+	}
+	enc.encodeStrings()
+	return enc, nil
+}
+
+// encodeStrings fills enc's table of UTF-8 encoded digits from its main
+// alphabet, so that encoding appends each rune's bytes rather than encoding
+// it to UTF-8 again every time. setNibblePad does the same for padding.
+func (enc *Encoding) encodeStrings() {
+	enc.digitStr = make([]string, len(enc.alphabet))
+	enc.digitLen = utf8.UTFMax
+	for i, r := range enc.alphabet {
+		enc.digitStr[i] = string(r)
+		enc.digitLen = min(enc.digitLen, len(enc.digitStr[i]))
+	}
+}
+
+// NewEncodingFromString is like NewEncoding but takes each alphabet as a
+// string, for alphabets read from flags or configuration files. Invalid UTF-8
+// in either string is rejected.
+func NewEncodingFromString(main, pad string) (*Encoding, error) {
+	for _, alphabet := range []string{main, pad} {
+		if !utf8.ValidString(alphabet) {
+			return nil, fmt.Errorf("%w: alphabet is not valid UTF-8", ErrAlphabetConflict)
+		}
+	}
+	return NewEncoding([]rune(main), []rune(pad))
+}
+
+// checkAlphabetRune reports whether r may appear in an alphabet.
+func checkAlphabetRune(r rune) error {
+	switch {
+	case !utf8.ValidRune(r):
+		return fmt.Errorf("%w: %U is not a valid Unicode scalar value", ErrAlphabetConflict, r)
+	case unicode.IsSpace(r):
+		return fmt.Errorf("%w: %U is whitespace", ErrAlphabetConflict, r)
+	case r == softHyphen:
+		return fmt.Errorf("%w: %U is skipped as a soft hyphen", ErrAlphabetConflict, r)
+	case unicode.IsControl(r):
+		return fmt.Errorf("%w: %U is a control character", ErrAlphabetConflict, r)
+	case isVariationSelector(r):
+		return fmt.Errorf("%w: %U is a variation selector", ErrAlphabetConflict, r)
+	}
+	return nil
+}
+
+// Clone returns a deep copy of enc, with its own alphabets and lookup tables.
+//
+// Encodings are immutable and the methods that configure them already return
+// copies, so customizing a shared encoding never needs Clone:
+// StdEncoding.Lenient() leaves StdEncoding untouched. Clone is for callers
+// that want an encoding sharing no storage with its source, such as one
+// derived from the package-level encodings and kept for the lifetime of a
+// request. Functions set by options, and a PadEncoding, are shared.
+func (enc *Encoding) Clone() *Encoding {
+	c := *enc
+	c.alphabet = slices.Clone(enc.alphabet)
+	c.digitStr = slices.Clone(enc.digitStr)
+	c.index = maps.Clone(enc.index)
+	c.padIndex = maps.Clone(enc.padIndex)
+	return &c
+}
+
+// Lenient returns a new encoding identical to enc except that decoding
+// silently strips Unicode variation selectors instead of rejecting them, and
+// reads the look-alikes ₿ (U+20BF) and Ƀ (U+0243) as the baht sign U+0E3F.
+func (enc Encoding) Lenient() *Encoding {
+	enc.lenient = true
+	return &enc
+}
+
+// WithSkipFunc returns a new encoding identical to enc except that decoding
+// skips every rune for which skip returns true, instead of the default space,
+// tab, carriage return, line feed and soft hyphen. This applies to Decode and to the
+// streaming decoders alike. A nil skip restores the default.
+//
+// The predicate must not return true for runes of either alphabet, or the
+// affected digits can no longer be decoded.
+func (enc Encoding) WithSkipFunc(skip func(rune) bool) *Encoding {
+	enc.skip = skip
+	return &enc
+}
+
+// WithFold returns a new encoding identical to enc except that decoding maps
+// every rune it keeps through fold before looking it up, so that variants of
+// an alphabet rune decode as that rune. For example, a custom alphabet of
+// upper-case Latin letters decodes lower-case input too with
+// WithFold(unicode.ToUpper). Encoding is unaffected, and a nil fold restores
+// the default of no folding, which is what StdEncoding uses.
+//
+// WithFold panics if fold maps a rune of either alphabet to anything but
+// itself, since that rune could then no longer be decoded.
+func (enc Encoding) WithFold(fold func(rune) rune) *Encoding {
+	if fold != nil {
+		for _, r := range enc.alphabet {
+			if fold(r) != r {
+				panic("padthai: fold changes alphabet rune " + string(r))
+			}
+		}
+		for r := range enc.padIndex {
+			if fold(r) != r {
+				panic("padthai: fold changes padding rune " + string(r))
+			}
+		}
+	}
+	enc.fold = fold
+	return &enc
+}
+
+// WithRuneMapper is WithFold, for callers that think of the hook as a way to
+// repair input, such as their own confusables or legacy substitutions, rather
+// than to fold case: mapper returns the alphabet rune a rune stands for, or
+// the rune itself to leave it alone.
+func (enc Encoding) WithRuneMapper(mapper func(rune) rune) *Encoding {
+	return enc.WithFold(mapper)
+}
+
+// WithExplicitTerminator returns a new encoding identical to enc except that
+// every encoding ends with the Buginese pair U+1A1E U+1A1F (pallawa, end of
+// section), whatever the length of the input. Decoding requires and consumes
+// the terminator, returning ErrMissingTerminator if it is absent. This adds 2
+// runes to every encoding but makes record boundaries easy to find in
+// concatenated output.
+//
+// WithExplicitTerminator panics if either terminator rune belongs to one of
+// enc's alphabets.
+func (enc Encoding) WithExplicitTerminator() *Encoding {
+	for _, r := range bugineseTerminator {
+		_, inMain := enc.index[r]
+		if inMain || enc.isPad(r) {
+			panic("padthai: terminator conflicts with alphabet")
+		}
+	}
+	enc.term = bugineseTerminator
+	return &enc
+}
+
+// WithGroupSize returns a new encoding identical to enc except that input is
+// consumed size bytes at a time. Size 2, the default, turns each byte pair
+// into 3 digits. Size 4 treats each 4-byte word as a big-endian uint32 and
+// turns it into 6 digits (48⁶ ≈ 1.2×10¹⁰ > 2³²), which keeps 32-bit aligned
+// data from being split across groups; up to 3 trailing bytes fall back to the
+// byte-pair and padding scheme.
+//
+// The output length is the same for both sizes, but the digits differ, so
+// data must be decoded with the group size it was encoded with.
+// WithGroupSize panics if size is not 2 or 4.
+func (enc Encoding) WithGroupSize(size int) *Encoding {
+	if size != 2 && size != 4 {
+		panic("padthai: invalid group size")
+	}
+	enc.group = size
+	return &enc
+}
+
+// ReverseDigits returns a new encoding identical to enc except that the
+// digits of each group are written least significant first, for interop with
+// implementations that do so. Only the order of digits within a group
+// changes: groups still follow each other in input order, and the bytes of a
+// group are still read big-endian.
+func (enc Encoding) ReverseDigits() *Encoding {
+	enc.reverse = true
+	return &enc
+}
+
+// WithMixedTail returns a new encoding identical to enc except that a
+// trailing odd byte is encoded as one main digit followed by a single padding
+// rune acting as the high-order digit, instead of two padding runes. The
+// output has the same length but half as many non-main runes, which helps
+// with renderers that handle the padding script poorly.
+//
+// Decoding does not detect the scheme: an encoding with the mixed tail
+// rejects a tail of two padding runes, and one without it rejects a tail of
+// one, so data must be decoded with the scheme it was encoded with.
+func (enc Encoding) WithMixedTail() *Encoding {
+	enc.tail = tailMixed
+	enc.padEnc = nil
+	return &enc
+}
+
+// WithZeroPadTrailer returns a new encoding identical to enc except that odd
+// input is completed with a zero byte and encoded as if it were even, followed
+// by a single zero digit marking the extra byte for the decoder to strip. The
+// output then uses the main alphabet only, at the cost of 2 more runes than
+// the padding scheme for odd input; even input is encoded as usual.
+//
+// WithZeroPadTrailer, WithMixedTail, WithThaiTail and WithPadEncoding are
+// mutually exclusive: the last one applied wins.
+func (enc Encoding) WithZeroPadTrailer() *Encoding {
+	enc.tail = tailZero
+	enc.padEnc = nil
+	return &enc
+}
+
+// WithThaiTail returns a new encoding identical to enc except that a trailing
+// odd byte is encoded as 2 main digits, since 256 values fit in base² of
+// them, instead of 2 padding runes. The output then uses the main alphabet
+// only, at the same length as the padding scheme.
+//
+// Digits otherwise come in triplets, so the decoder recognizes the tail by
+// the number of digits: 2 more than a multiple of 3.
+func (enc Encoding) WithThaiTail() *Encoding {
+	enc.tail = tailThai
+	enc.padEnc = nil
+	return &enc
+}
+
+// EncodingInfo describes an Encoding, for instance to let users compare
+// custom alphabets before choosing one.
+type EncodingInfo struct {
+	Base    int // radix of the main alphabet
+	PadBase int // size of the padding alphabet
+
+	// RunesPerByte is the asymptotic number of runes emitted per input byte.
+	RunesPerByte float64
+
+	// UTF8BytesPerByte is the asymptotic number of UTF-8 bytes emitted per
+	// input byte, assuming every digit is equally likely.
+	UTF8BytesPerByte float64
+}
+
+// Info returns metadata about enc derived from its alphabets.
+func (enc *Encoding) Info() EncodingInfo {
+	width := 0
+	for _, r := range enc.alphabet {
+		width += utf8.RuneLen(r)
+	}
+	runesPerByte := 1.5 // 3 runes per 2 bytes, whatever the group size
+	return EncodingInfo{
+		Base:             int(enc.base),
+		PadBase:          PadBase,
+		RunesPerByte:     runesPerByte,
+		UTF8BytesPerByte: runesPerByte * float64(width) / float64(len(enc.alphabet)),
+	}
+}
+
+// ThaiAlphabetString returns ThaiAlphabet as a string, in digit order.
+func ThaiAlphabetString() string {
+	return string(ThaiAlphabet[:])
+}
+
+// BugineseAlphabetString returns BugineseAlphabet as a string, in nibble order.
+func BugineseAlphabetString() string {
+	return string(BugineseAlphabet[:])
 }
 
 // isThai returns true if r is one of the 48 Thai encoding characters.
@@ -70,37 +543,247 @@ func isBuginese(r rune) bool {
 	return r >= bugineseStart && r <= bugineseEnd
 }
 
+// asciiSpace marks the whitespace characters skipped by default when decoding.
+var asciiSpace = [utf8.RuneSelf]bool{' ': true, '\n': true, '\r': true, '\t': true}
+
+// softHyphen is U+00AD SOFT HYPHEN, which some wrapping tools insert at line
+// breaks. It is invisible unless a line breaks there, so it is skipped like
+// whitespace.
+const softHyphen = '\u00ad'
+
+// isSpace returns true if r is one of the characters skipped by default when
+// decoding: ASCII whitespace and the soft hyphen.
+func isSpace(r rune) bool {
+	return (r < utf8.RuneSelf && asciiSpace[r]) || r == softHyphen
+}
+
+// isVariationSelector returns true if r is in one of the Unicode variation
+// selector blocks (VS1–VS16 or VS17–VS256).
+func isVariationSelector(r rune) bool {
+	return (r >= '\ufe00' && r <= '\ufe0f') || (r >= '\U000e0100' && r <= '\U000e01ef')
+}
+
+// EncodedRuneLen returns the number of runes in the encoding of n input
+// bytes: 3 per byte pair plus 2 for a trailing odd byte. It returns -1 if n is
+// negative or the result would overflow an int.
+func EncodedRuneLen(n int) int {
+	if n < 0 || n/2 > (math.MaxInt-2)/3 {
+		return -1
+	}
+	return n/2*3 + n%2*2
+}
+
+// EncodedByteLen returns the length in bytes of the UTF-8 encoding of n input
+// bytes. Every Thai and Buginese rune takes 3 bytes in UTF-8. It returns -1 if
+// n is negative or the result would overflow an int.
+func EncodedByteLen(n int) int {
+	runes := EncodedRuneLen(n)
+	if runes < 0 || runes > math.MaxInt/3 {
+		return -1
+	}
+	return runes * 3
+}
+
+// EstimateDecodedSize returns the number of bytes s would decode to, from a
+// single pass counting its non-whitespace runes: 2 bytes per 3 runes, plus 1
+// if 2 runes of trailing padding remain. It does not validate the characters,
+// so the estimate is only exact for valid input.
+func EstimateDecodedSize(s string) int {
+	n := 0
+	for _, r := range s {
+		if !isSpace(r) {
+			n++
+		}
+	}
+	size := n / 3 * 2
+	if n%3 == 2 {
+		size++
+	}
+	return size
+}
+
+// DecodedLenBounds returns the smallest and largest number of bytes s could
+// decode to, from a single pass counting its non-whitespace runes, so that a
+// length limit can be checked before decoding. StdEncoding output has a
+// deterministic length, so min equals max; the pair leaves room for encodings
+// whose length is not. Like EstimateDecodedSize, it does not validate the
+// characters, but it returns an error if no valid input has as many runes.
+func DecodedLenBounds(s string) (min, max int, err error) {
+	n := 0
+	for _, r := range s {
+		if !isSpace(r) {
+			n++
+		}
+	}
+	if n%3 == 1 {
+		return 0, 0, &DecodeError{Kind: InvalidLength, RuneOffset: n, ByteOffset: len(s), Rune: -1,
+			Err: fmt.Errorf("%w: %d characters is neither a multiple of 3 nor 2 more", ErrInvalidLength, n)}
+	}
+	size := n/3*2 + n%3/2
+	return size, size, nil
+}
+
+// IsCanonicalTriplet reports whether the digits d0, d1 and d2, most
+// significant first, form a triplet that Decode accepts: each digit is in
+// [0, Base) and their value does not exceed MaxTripletValue. Only 65,536 of
+// the TripletCombinations triplets are canonical.
+func IsCanonicalTriplet(d0, d1, d2 int) bool {
+	for _, d := range [3]int{d0, d1, d2} {
+		if d < 0 || d >= Base {
+			return false
+		}
+	}
+	return (d0*Base+d1)*Base+d2 <= MaxTripletValue
+}
+
+// Encode encodes a byte slice into a padthai string using StdEncoding.
+func Encode(data []byte) string {
+	return StdEncoding.Encode(data)
+}
+
+// Decode decodes a padthai-encoded string using StdEncoding.
+func Decode(s string) ([]byte, error) {
+	return StdEncoding.Decode(s)
+}
+
 // Encode encodes a byte slice into a padthai string.
 //
 // Every 2 input bytes are treated as a big-endian 16-bit integer and converted
 // to 3 base-48 digits (most-significant first), each mapped to a Thai character.
 //
 // A trailing single byte is encoded as 2 Buginese characters (high nibble, low nibble).
-func Encode(data []byte) string {
+//
+// The output of the built-in encodings is always in Unicode Normalization
+// Form C, so NFC normalization, which most systems that normalize apply, is a
+// no-op on it. That of StdEncoding and NoBahtEncoding is in Form D too, but
+// CompactEncoding has the digits Й and й, which NFD decomposes and decoding
+// then rejects. Custom alphabets carry no such guarantee.
+func (enc *Encoding) Encode(data []byte) string {
+	return encode(enc, data)
+}
+
+// encode implements Encoding.Encode for both byte slices and strings, so that
+// string input need not be copied first.
+func encode[T string | []byte](enc *Encoding, data T) string {
 	if len(data) == 0 {
-		return ""
+		return enc.term
+	}
+	if enc.group == 2 && enc.tail == tailPadded && enc.padEnc == nil {
+		if len(data) <= tinyInput {
+			return encodeTiny(enc, data)
+		}
+		return encodeDirect(enc, data)
+	}
+
+	var sb strings.Builder
+	// Pre-allocate the exact size for 3-byte runes; skip the hint entirely
+	// if it would overflow (only possible for huge inputs on 32-bit targets)
+	if n := EncodedByteLen(len(data)); n > 0 {
+		sb.Grow(n)
 	}
+	encodeTo(enc, &sb, data)
+	sb.WriteString(enc.term)
+	return sb.String()
+}
 
+// encodeDirect is encode for the default group and tail schemes. It writes
+// to a strings.Builder directly rather than through encodeTo, appending the
+// UTF-8 bytes of each rune from enc's tables: this avoids both encoding every
+// rune to UTF-8 again and a runeWriter method call per rune, and keeps the
+// builder on the stack.
+func encodeDirect[T string | []byte](enc *Encoding, data T) string {
 	var sb strings.Builder
-	// Pre-allocate: each 2-byte pair -> 3 runes (up to 3 bytes each in UTF-8)
-	// worst case ~4.5x expansion, plus possible 2 Buginese chars
-	sb.Grow(len(data)*5 + 6)
+	if n := EncodedByteLen(len(data)); n > 0 {
+		sb.Grow(n)
+	}
+	i := 0
+	for ; i+1 < len(data); i += 2 {
+		d0, d1, d2 := enc.tripletDigits(uint(data[i])<<8 | uint(data[i+1]))
+		if enc.reverse {
+			d0, d2 = d2, d0
+		}
+		sb.WriteString(enc.digitString(d0))
+		sb.WriteString(enc.digitString(d1))
+		sb.WriteString(enc.digitString(d2))
+	}
+	if i < len(data) {
+		sb.WriteString(enc.padStr[data[i]>>4])
+		sb.WriteString(enc.padStr[data[i]&0x0f])
+	}
+	sb.WriteString(enc.term)
+	return sb.String()
+}
 
+// tinyInput is the largest input encode handles with encodeTiny, and
+// tinyEncoded the buffer that needs: 3 runes per byte pair and 2 for an odd
+// byte, of up to 4 bytes each, and a terminator.
+const (
+	tinyInput   = 16
+	tinyEncoded = (tinyInput/2*3+2)*utf8.UTFMax + len(bugineseTerminator)
+)
+
+// encodeTiny is encode for inputs of at most tinyInput bytes, in the default
+// group and tail schemes. It encodes into a stack buffer, so that the result
+// string is its only allocation, which matters when encoding many short IDs:
+// a strings.Builder passed to encodeTo as a runeWriter escapes to the heap.
+func encodeTiny[T string | []byte](enc *Encoding, data T) string {
+	var buf [tinyEncoded]byte
+	out := buf[:0]
 	i := 0
+	for ; i+1 < len(data); i += 2 {
+		d0, d1, d2 := enc.tripletDigits(uint(data[i])<<8 | uint(data[i+1]))
+		if enc.reverse {
+			d0, d2 = d2, d0
+		}
+		out = append(out, enc.digitString(d0)...)
+		out = append(out, enc.digitString(d1)...)
+		out = append(out, enc.digitString(d2)...)
+	}
+	if i < len(data) {
+		out = append(out, enc.padStr[data[i]>>4]...)
+		out = append(out, enc.padStr[data[i]&0x0f]...)
+	}
+	out = append(out, enc.term...)
+	return string(out)
+}
+
+// runeWriter is the output of encodeTo. *strings.Builder implements it.
+type runeWriter interface {
+	WriteRune(r rune) (int, error)
+	WriteString(s string) (int, error)
+}
+
+// encodeTo writes the encoding of data to w, rune by rune, leaving out the
+// terminator.
+func encodeTo[T string | []byte](enc *Encoding, w runeWriter, data T) {
+	i := 0
+	if enc.group == 4 {
+		for i+3 < len(data) {
+			word := uint64(data[i])<<24 | uint64(data[i+1])<<16 | uint64(data[i+2])<<8 | uint64(data[i+3])
+			enc.writeDigits(w, word, 6)
+			i += 4
+		}
+		// A zero-padded trailer completes 3 trailing bytes to a word
+		if enc.tail == tailZero && len(data)-i == 3 {
+			word := uint64(data[i])<<24 | uint64(data[i+1])<<16 | uint64(data[i+2])<<8
+			enc.writeDigits(w, word, 6)
+			i += 3
+		}
+	}
+
 	for i+1 < len(data) {
 		// Take 2 bytes as a big-endian uint16
 		val := uint(data[i])<<8 | uint(data[i+1])
 
 		// Convert to 3 base-48 digits, most significant first
-		d2 := val % Base
-		val /= Base
-		d1 := val % Base
-		val /= Base
-		d0 := val // val < 65536 and 48^3 = 110592, so d0 < 48
+		d0, d1, d2 := enc.tripletDigits(val)
+		if enc.reverse {
+			d0, d2 = d2, d0
+		}
 
-		sb.WriteRune(ThaiAlphabet[d0])
-		sb.WriteRune(ThaiAlphabet[d1])
-		sb.WriteRune(ThaiAlphabet[d2])
+		w.WriteRune(enc.digitRune(d0))
+		w.WriteRune(enc.digitRune(d1))
+		w.WriteRune(enc.digitRune(d2))
 
 		i += 2
 	}
@@ -108,86 +791,594 @@ func Encode(data []byte) string {
 	// Handle trailing single byte with Buginese padding
 	if i < len(data) {
 		b := data[i]
-		hi := (b >> 4) & 0x0f
-		lo := b & 0x0f
-		sb.WriteRune(BugineseAlphabet[hi])
-		sb.WriteRune(BugineseAlphabet[lo])
+		switch enc.tail {
+		case tailMixed:
+			// b < 256 <= 16*base, so the quotient fits in a padding rune
+			w.WriteRune(enc.digitRune(uint(b) % enc.base))
+			w.WriteRune(enc.pad[uint(b)/enc.base])
+		case tailZero:
+			enc.writeDigits(w, uint64(b)<<8, 3)
+		case tailThai:
+			enc.writeDigits(w, uint64(b), 2)
+		default:
+			if enc.padEnc != nil {
+				for _, r := range enc.padEnc.Encode(b) {
+					w.WriteRune(r)
+				}
+				break
+			}
+			hi := (b >> 4) & 0x0f
+			lo := b & 0x0f
+			w.WriteRune(enc.pad[hi])
+			w.WriteRune(enc.pad[lo])
+		}
+	}
+	if enc.tail == tailZero && len(data)%2 == 1 {
+		w.WriteRune(enc.alphabet[0])
 	}
+}
 
-	return sb.String()
+// digitRune returns the rune for digit d. An out-of-range digit is a bug in
+// the digit arithmetic or in how enc was built, and panics with a message
+// saying so rather than a bare index error.
+func (enc *Encoding) digitRune(d uint) rune {
+	if d >= uint(len(enc.alphabet)) {
+		digitRangePanic(d, enc.base)
+	}
+	return enc.alphabet[d]
+}
+
+// digitString is like digitRune but returns the digit's UTF-8 encoding.
+func (enc *Encoding) digitString(d uint) string {
+	if d >= uint(len(enc.digitStr)) {
+		digitRangePanic(d, enc.base)
+	}
+	return enc.digitStr[d]
+}
+
+// digitRangePanic is kept out of digitRune and digitString so that they are
+// inlined.
+//
+//go:noinline
+func digitRangePanic(d, base uint) {
+	panic(fmt.Sprintf("padthai: digit %d out of range for base %d", d, base))
+}
+
+// tripletDigits splits the 16-bit value val into 3 digits, most significant
+// first.
+func (enc *Encoding) tripletDigits(val uint) (d0, d1, d2 uint) {
+	if enc.base == Base {
+		// Division by a constant compiles to a multiply and a shift
+		rem := val % (Base * Base)
+		return val / (Base * Base), rem / Base, rem % Base
+	}
+	d2 = val % enc.base
+	val /= enc.base
+	d1 = val % enc.base
+	val /= enc.base
+	d0 = val // val < 65536 and base^3 >= 68921, so d0 < base
+	return d0, d1, d2
+}
+
+// writeDigits writes val as n digits, most significant first unless enc
+// reverses digits.
+func (enc *Encoding) writeDigits(w runeWriter, val uint64, n int) {
+	var digits [6]rune
+	for j := n - 1; j >= 0; j-- {
+		digits[j] = enc.digitRune(uint(val % uint64(enc.base)))
+		val /= uint64(enc.base)
+	}
+	if enc.reverse {
+		slices.Reverse(digits[:n])
+	}
+	for _, r := range digits[:n] {
+		w.WriteRune(r)
+	}
 }
 
 // Decode decodes a padthai-encoded string back into the original bytes.
 //
-// Whitespace characters (spaces, tabs, newlines) are silently skipped.
-// Returns an error if the input contains invalid characters or has an
-// invalid structure.
-func Decode(s string) ([]byte, error) {
-	// Collect runes, skipping whitespace
-	runes := make([]rune, 0, utf8.RuneCountInString(s))
-	for _, r := range s {
-		switch r {
-		case ' ', '\n', '\r', '\t':
+// Whitespace characters (spaces, tabs, newlines) and soft hyphens (U+00AD)
+// are silently skipped, or whatever runes enc's skip predicate selects; see
+// WithSkipFunc.
+// Variation selectors are rejected with ErrVariationSelector unless enc is
+// lenient, in which case they are skipped too.
+// Returns ErrInvalidUTF8 if s is not valid UTF-8, and an error if the input
+// contains invalid characters or has an invalid structure. If s ends part-way
+// through a UTF-8 sequence, as when a transfer is cut short, the error also
+// wraps io.ErrUnexpectedEOF. Errors about the input are *DecodeError.
+func (enc *Encoding) Decode(s string) ([]byte, error) {
+	data, err := enc.decode(s)
+	if err != nil {
+		enc.locate(s, err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// locate sets the byte offset of err, a decode error for s, if it is a
+// *DecodeError whose byte offset is not yet known.
+func (enc *Encoding) locate(s string, err error) {
+	var de *DecodeError
+	if !errors.As(err, &de) || de.ByteOffset >= 0 {
+		return
+	}
+	kept := 0
+	for i, r := range s {
+		// A rune accept rejects is at the position it would have been kept at
+		keep, rejected := enc.accept(r, kept)
+		if !keep && rejected == nil {
 			continue
-		default:
+		}
+		if kept == de.RuneOffset {
+			de.ByteOffset = i
+			return
+		}
+		if keep {
+			kept++
+		}
+	}
+	de.ByteOffset = len(s)
+}
+
+// decode implements Decode.
+func (enc *Encoding) decode(s string) ([]byte, error) {
+	var out []byte
+	start, pos := 0, 0
+	// Decode the body of the input in a single pass where the layout allows,
+	// leaving the general path below only the tail, or the rest of the input
+	// from the first rune that needs attention.
+	if enc.group == 2 && enc.tail != tailZero && enc.skip == nil && enc.fold == nil {
+		out, start, pos = enc.decodeTriplets(s)
+	}
+
+	// Collect runes, skipping whitespace. The slice is sized for the runes
+	// kept rather than the whole input, so that padding the input with
+	// whitespace cannot make Decode allocate more than the data warrants.
+	// Short inputs are collected on the stack.
+	var small [tinyRunes]rune
+	runes := small[:0]
+	if n := keptRuneCount(s[start:]); n > len(small) {
+		runes = make([]rune, 0, n)
+	}
+	for i, r := range s[start:] {
+		// Fast path for the default skip set: below the variation selectors,
+		// a rune is skipped exactly when it is whitespace or a soft hyphen
+		if enc.skip == nil && enc.fold == nil && r < '\ufe00' {
+			if isSpace(r) {
+				continue
+			}
+			runes = append(runes, r)
+			continue
+		}
+		// A literal U+FFFD takes 3 bytes; an invalid byte decodes to it too
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[start+i:]); size == 1 {
+				// The start of a rune cut off by the end of the input is a
+				// sign of truncation rather than corruption
+				if !utf8.FullRuneInString(s[start+i:]) {
+					return nil, &DecodeError{Kind: InvalidUTF8, RuneOffset: pos + len(runes), ByteOffset: start + i, Rune: utf8.RuneError,
+						Err: fmt.Errorf("%w: %w: byte %#02x at offset %d starts a rune cut off by the end of the input",
+							io.ErrUnexpectedEOF, ErrInvalidUTF8, s[start+i], start+i)}
+				}
+				return nil, &DecodeError{Kind: InvalidUTF8, RuneOffset: pos + len(runes), ByteOffset: start + i, Rune: utf8.RuneError,
+					Err: fmt.Errorf("%w: byte %#02x at offset %d", ErrInvalidUTF8, s[start+i], start+i)}
+			}
+		}
+		keep, err := enc.accept(r, pos+len(runes))
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			if enc.fold != nil {
+				r = enc.fold(r)
+			}
 			runes = append(runes, r)
 		}
 	}
 
-	if len(runes) == 0 {
+	// Reject a length no encoding has before decoding the rest, now that
+	// the runes kept are counted. A lone padding rune at the end is left to
+	// report as ErrTruncatedPadding.
+	if (enc.tail == tailPadded && enc.padEnc == nil || enc.tail == tailThai) && enc.term == "" && enc.skip == nil && enc.fold == nil {
+		if n := pos + len(runes); n%3 == 1 && !enc.isPad(runes[len(runes)-1]) {
+			return nil, corrupt(InvalidLength, n, -1, fmt.Errorf("%w: %d characters is neither a multiple of 3 nor 2 more", ErrInvalidLength, n))
+		}
+	}
+
+	if out == nil {
+		return enc.decodeKept(runes)
+	}
+	if len(runes) == 0 && enc.term == "" {
+		return out, nil
+	}
+	return enc.decodeRunes(out, runes, pos)
+}
+
+// decodeTriplets decodes the leading triplets of s in a single pass, with no
+// intermediate slice of runes. It skips only the default whitespace, and
+// stops at the first rune that is not a digit, such as padding, or at a
+// triplet that is not canonical. It returns the decoded bytes, nil if there
+// are none, along with the byte offset in s and the position in runes kept
+// of the input it did not decode, which must be left to decodeRunes.
+func (enc *Encoding) decodeTriplets(s string) (out []byte, start, pos int) {
+	var d [3]int
+	n := 0
+	for i, r := range s {
+		if r < '\ufe00' && isSpace(r) {
+			continue
+		}
+		v, ok := enc.digit(r)
+		if !ok {
+			break
+		}
+		if d[n] = v; n < 2 {
+			n++
+			continue
+		}
+		n = 0
+		if enc.reverse {
+			d[0], d[2] = d[2], d[0]
+		}
+		val := (uint(d[0])*enc.base+uint(d[1]))*enc.base + uint(d[2])
+		if val > MaxTripletValue {
+			break // for decodeRunes to report
+		}
+		if out == nil {
+			// Size the output for s holding nothing but digits, as in
+			// decodeKept, so that decoding makes a single allocation
+			out = make([]byte, 0, len(s)/enc.digitLen/3*2+1)
+		}
+		out = append(out, byte(val>>8), byte(val))
+		start, pos = i+utf8.RuneLen(r), pos+3
+	}
+	return out, start, pos
+}
+
+// decodeKept decodes the whole input, given as the runes a decoder keeps.
+func (enc *Encoding) decodeKept(runes []rune) ([]byte, error) {
+	if len(runes) == 0 && enc.term == "" {
 		return []byte{}, nil
 	}
 
-	// Determine how many trailing Buginese characters we have (0 or 2)
-	trailingBuginese := 0
-	if len(runes) >= 2 && isBuginese(runes[len(runes)-1]) && isBuginese(runes[len(runes)-2]) {
-		trailingBuginese = 2
+	// Pre-allocate output: each 3 Thai chars -> 2 bytes, plus maybe 1 byte from Buginese
+	out := make([]byte, 0, len(runes)/3*2+1)
+	return enc.decodeRunes(out, runes, 0)
+}
+
+// DecodeStrict decodes s using StdEncoding, like Decode, except that input
+// consisting only of whitespace is an error, ErrNoContent, rather than the
+// encoding of nothing. The empty string still decodes to an empty slice.
+func DecodeStrict(s string) ([]byte, error) {
+	return StdEncoding.DecodeStrict(s)
+}
+
+// DecodeMeta is like Decode but also reports whether s ended in Buginese
+// padding, the form Encode gives a trailing odd byte, for tools that check
+// or reproduce the structure of their input. With StdEncoding, the padding
+// is present exactly when the decoded data has an odd length.
+func DecodeMeta(s string) (data []byte, hadPadding bool, err error) {
+	data, err = Decode(s)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, len(data)%2 == 1, nil
+}
+
+// DecodeStrict is like the package-level DecodeStrict but uses enc. Input
+// holding only runes that enc skips is rejected with ErrNoContent.
+func (enc *Encoding) DecodeStrict(s string) ([]byte, error) {
+	data, err := enc.Decode(s)
+	// With a terminator, skipped runes alone already fail to decode
+	if err == nil && len(data) == 0 && s != "" && enc.term == "" {
+		return nil, &DecodeError{Kind: InvalidLength, ByteOffset: len(s), Rune: -1,
+			Err: fmt.Errorf("%w: all %d bytes were skipped", ErrNoContent, len(s))}
+	}
+	return data, err
+}
+
+// tinyRunes is the number of kept runes Decode collects without allocating.
+const tinyRunes = 32
+
+// keptRuneCount returns the number of runes in s that are not ASCII
+// whitespace, the runes the default skip set keeps. It only sizes buffers,
+// so it need not be exact for invalid UTF-8.
+func keptRuneCount(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		// Count rune starts, leaving out continuation bytes and whitespace
+		if c&0xC0 != 0x80 && !(c < utf8.RuneSelf && asciiSpace[c]) {
+			n++
+		}
+	}
+	return n
+}
+
+// Skips reports whether decoding with enc ignores r: whitespace and soft
+// hyphens, or the runes of WithSkipFunc, and variation selectors if enc is
+// lenient. Tools that reformat padthai without decoding it, such as a
+// rewrapper, can use it to drop exactly what the decoder would.
+func (enc *Encoding) Skips(r rune) bool {
+	keep, err := enc.accept(r, 0)
+	return !keep && err == nil
+}
+
+// accept reports whether a decoder should keep the input rune r, which would
+// be at position pos, or skip it. It returns an error for runes that must be
+// neither kept nor skipped.
+func (enc *Encoding) accept(r rune, pos int) (bool, error) {
+	if enc.skip != nil {
+		if enc.skip(r) {
+			return false, nil
+		}
+	} else if isSpace(r) {
+		return false, nil
+	}
+	if isVariationSelector(r) {
+		if enc.lenient {
+			return false, nil
+		}
+		return false, corrupt(InvalidChar, pos, r, fmt.Errorf("%w %U at position %d", ErrVariationSelector, r, pos))
+	}
+	return true, nil
+}
+
+// decodeRunes decodes runes and appends the result to dst. The runes must be
+// the whole rest of the input from a group boundary, with skipped runes
+// removed; pos is the position of runes[0] in the input.
+func (enc *Encoding) decodeRunes(dst []byte, runes []rune, pos int) ([]byte, error) {
+	if enc.term != "" {
+		n := len(runes) - 2
+		if n < 0 || string(runes[n:]) != enc.term {
+			return nil, corrupt(InvalidLength, pos+len(runes), -1, fmt.Errorf("%w after position %d", ErrMissingTerminator, pos+len(runes)))
+		}
+		runes = runes[:n]
 	}
 
-	thaiRunes := runes[:len(runes)-trailingBuginese]
-	bugRunes := runes[len(runes)-trailingBuginese:]
+	switch enc.tail {
+	case tailMixed:
+		return enc.decodeMixedTail(dst, runes, pos)
+	case tailZero:
+		return enc.decodeZeroPadTrailer(dst, runes, pos)
+	case tailThai:
+		return enc.decodeThaiTail(dst, runes, pos)
+	}
+	if enc.padEnc != nil {
+		return enc.decodeCustomPad(dst, runes, pos)
+	}
+
+	// The padding region is every padding rune at the end: none, or exactly
+	// 2 Buginese characters for a trailing odd byte
+	n := len(runes)
+	for n > 0 && enc.isPad(runes[n-1]) {
+		n--
+	}
+	thaiRunes, bugRunes := runes[:n], runes[n:]
+
+	switch len(bugRunes) {
+	case 0, 2:
+	case 1:
+		lone := n
+		hint := "one more padding character would complete it"
+		if (pos+lone)%3 != 0 {
+			hint = "the preceding digits are incomplete too"
+		}
+		return nil, corrupt(BadPadding, pos+lone, runes[lone], fmt.Errorf("%w: lone padding character %U at position %d; %s", ErrTruncatedPadding, runes[lone], pos+lone, hint))
+	default:
+		return nil, corrupt(BadPadding, pos+n, runes[n], fmt.Errorf("%w: %d padding characters at position %d, want 2", ErrInvalidPadding, len(bugRunes), pos+n))
+	}
 
 	if len(thaiRunes)%3 != 0 {
-		return nil, fmt.Errorf("padthai: invalid encoded length: %d Thai characters is not a multiple of 3", len(thaiRunes))
+		return nil, corrupt(InvalidLength, pos+len(thaiRunes), -1, fmt.Errorf("%w: %d Thai characters is not a multiple of 3", ErrInvalidLength, pos+len(thaiRunes)))
 	}
 
-	// Pre-allocate output: each 3 Thai chars -> 2 bytes, plus maybe 1 byte from Buginese
-	out := make([]byte, 0, (len(thaiRunes)/3)*2+trailingBuginese/2)
+	dst, err := enc.decodeGroups(dst, thaiRunes, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode Buginese padding (single trailing byte); padIndex only holds
+	// nibbles, so any 2 padding runes form a valid byte
+	if len(bugRunes) == 2 {
+		hi := byte(enc.padIndex[bugRunes[0]])
+		lo := byte(enc.padIndex[bugRunes[1]])
+		dst = append(dst, (hi<<4)|lo)
+	}
+
+	return dst, nil
+}
+
+// decodeMixedTail is decodeRunes for encodings with a mixed tail, where a
+// trailing odd byte is a main digit followed by a single padding rune.
+func (enc *Encoding) decodeMixedTail(dst []byte, runes []rune, pos int) ([]byte, error) {
+	n := len(runes)
+	hasTail := n > 0 && enc.isPad(runes[n-1])
+	if hasTail {
+		n -= 2
+		if n < 0 {
+			return nil, corrupt(BadPadding, pos, runes[0], fmt.Errorf("%w: mixed tail has no digit before padding character at position %d", ErrInvalidPadding, pos))
+		}
+		if enc.isPad(runes[n]) {
+			return nil, corrupt(BadPadding, pos+n, runes[n], fmt.Errorf("%w: mixed tail has padding character %U at position %d", ErrInvalidPadding, runes[n], pos+n))
+		}
+	}
+	if n%3 != 0 {
+		return nil, corrupt(InvalidLength, pos+n, -1, fmt.Errorf("%w: %d Thai characters is not a multiple of 3", ErrInvalidLength, pos+n))
+	}
+
+	dst, err := enc.decodeGroups(dst, runes[:n], pos)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasTail {
+		val, err := enc.decodeDigits(runes[n:n+1], pos+n, math.MaxUint64)
+		if err != nil {
+			return nil, err
+		}
+		val += uint64(enc.padIndex[runes[n+1]]) * uint64(enc.base)
+		if val > 0xFF {
+			return nil, corrupt(OutOfRange, pos+n, runes[n], fmt.Errorf("%w: mixed tail at position %d decodes to %d, above 0xFF", ErrNonCanonical, pos+n, val))
+		}
+		dst = append(dst, byte(val))
+	}
+	return dst, nil
+}
+
+// decodeZeroPadTrailer is decodeRunes for encodings with a zero-padded
+// trailer, where a final marker digit says the last decoded byte is padding.
+func (enc *Encoding) decodeZeroPadTrailer(dst []byte, runes []rune, pos int) ([]byte, error) {
+	n := len(runes)
+	odd := n%3 == 1
+	if odd {
+		n--
+		if d, ok := enc.digit(runes[n]); !ok || d != 0 || n == 0 {
+			return nil, corrupt(BadPadding, pos+n, runes[n], fmt.Errorf("%w: invalid trailer marker %U at position %d", ErrInvalidPadding, runes[n], pos+n))
+		}
+	}
+	if n%3 != 0 {
+		return nil, corrupt(InvalidLength, pos+n, -1, fmt.Errorf("%w: %d Thai characters is not a multiple of 3", ErrInvalidLength, pos+n))
+	}
+
+	dst, err := enc.decodeGroups(dst, runes[:n], pos)
+	if err != nil {
+		return nil, err
+	}
+	if odd {
+		if dst[len(dst)-1] != 0 {
+			return nil, corrupt(OutOfRange, pos+n-3, runes[n-3], fmt.Errorf("%w: trailer padding byte is %#02x, not zero, before position %d", ErrNonCanonical, dst[len(dst)-1], pos+n))
+		}
+		dst = dst[:len(dst)-1]
+	}
+	return dst, nil
+}
+
+// decodeThaiTail is decodeRunes for encodings with a Thai tail, where a
+// trailing odd byte is 2 main digits.
+func (enc *Encoding) decodeThaiTail(dst []byte, runes []rune, pos int) ([]byte, error) {
+	n := len(runes)
+	odd := n%3 == 2
+	if odd {
+		n -= 2
+	}
+	if n%3 != 0 {
+		return nil, corrupt(InvalidLength, pos+len(runes), -1, fmt.Errorf("%w: %d Thai characters is neither a multiple of 3 nor 2 more", ErrInvalidLength, pos+len(runes)))
+	}
+
+	dst, err := enc.decodeGroups(dst, runes[:n], pos)
+	if err != nil {
+		return nil, err
+	}
+	if odd {
+		val, err := enc.decodeDigits(runes[n:], pos+n, 0xFF)
+		if err != nil {
+			return nil, err
+		}
+		dst = append(dst, byte(val))
+	}
+	return dst, nil
+}
+
+// decodeGroups decodes runes, a whole number of triplets, and appends the
+// result to dst. pos is the position of runes[0] in the input.
+func (enc *Encoding) decodeGroups(dst []byte, runes []rune, pos int) ([]byte, error) {
+	i := 0
+	if enc.group == 4 {
+		// Decode 6-digit words; a remaining triplet is a trailing byte pair
+		for ; i+5 < len(runes); i += 6 {
+			val, err := enc.decodeDigits(runes[i:i+6], pos+i, math.MaxUint32)
+			if err != nil {
+				return nil, err
+			}
+			dst = binary.BigEndian.AppendUint32(dst, uint32(val))
+		}
+	}
 
 	// Decode Thai triplets
-	for i := 0; i+2 < len(thaiRunes); i += 3 {
-		d0, ok0 := thaiIndex[thaiRunes[i]]
-		d1, ok1 := thaiIndex[thaiRunes[i+1]]
-		d2, ok2 := thaiIndex[thaiRunes[i+2]]
+	for ; i+2 < len(runes); i += 3 {
+		d0, ok0 := enc.digit(runes[i])
+		d1, ok1 := enc.digit(runes[i+1])
+		d2, ok2 := enc.digit(runes[i+2])
 		if !ok0 || !ok1 || !ok2 {
-			pos := i
+			bad := i
 			if !ok0 {
-				// pos is i
+				// bad is i
 			} else if !ok1 {
-				pos = i + 1
+				bad = i + 1
 			} else {
-				pos = i + 2
+				bad = i + 2
 			}
-			return nil, fmt.Errorf("padthai: invalid character %U at position %d", thaiRunes[pos], pos)
+			if enc.isPad(runes[bad]) {
+				return nil, corrupt(BadPadding, pos+bad, runes[bad], fmt.Errorf("%w: padding character %U at position %d is not at the end of the input", ErrInvalidPadding, runes[bad], pos+bad))
+			}
+			return nil, corrupt(InvalidChar, pos+bad, runes[bad], fmt.Errorf("%w %U at position %d", ErrInvalidCharacter, runes[bad], pos+bad))
+		}
+		if enc.reverse {
+			d0, d2 = d2, d0
 		}
 
-		val := uint(d0)*Base*Base + uint(d1)*Base + uint(d2)
-		if val > 0xFFFF {
-			return nil, fmt.Errorf("padthai: decoded value %d exceeds 16-bit range at position %d", val, i)
+		val := (uint(d0)*enc.base+uint(d1))*enc.base + uint(d2)
+		if val > MaxTripletValue {
+			return nil, corrupt(OutOfRange, pos+i, runes[i], fmt.Errorf("%w: triplet %c%c%c at position %d has digits [%d %d %d], decoding to %d, above 0xFFFF",
+				ErrNonCanonical, runes[i], runes[i+1], runes[i+2], pos+i, d0, d1, d2, val))
 		}
 
-		out = append(out, byte(val>>8), byte(val&0xFF))
+		dst = append(dst, byte(val>>8), byte(val&0xFF))
 	}
 
-	// Decode Buginese padding (single trailing byte)
-	if trailingBuginese == 2 {
-		hi := byte(bugRunes[0] - bugineseStart)
-		lo := byte(bugRunes[1] - bugineseStart)
-		if hi > 0x0f || lo > 0x0f {
-			return nil, fmt.Errorf("padthai: invalid Buginese padding character")
+	return dst, nil
+}
+
+// decodeDigits decodes runes as a single number, most significant digit
+// first unless enc reverses digits. pos is the position of runes[0] in the
+// input, for error reporting. It returns an error if the value exceeds max.
+func (enc *Encoding) decodeDigits(runes []rune, pos int, max uint64) (uint64, error) {
+	var val uint64
+	for k := range runes {
+		j := k
+		if enc.reverse {
+			j = len(runes) - 1 - k
+		}
+		r := runes[j]
+		d, ok := enc.digit(r)
+		if !ok {
+			return 0, corrupt(InvalidChar, pos+j, r, fmt.Errorf("%w %U at position %d", ErrInvalidCharacter, r, pos+j))
 		}
-		out = append(out, (hi<<4)|lo)
+		// Saturate rather than overflow for very large custom bases
+		if val <= max {
+			val = val*uint64(enc.base) + uint64(d)
+		}
+	}
+	if val > max {
+		return 0, corrupt(OutOfRange, pos, runes[0], fmt.Errorf("%w: %d digits %s at position %d exceed the %d-bit range", ErrNonCanonical, len(runes), string(runes), pos, bits.Len64(max)))
+	}
+	return val, nil
+}
+
+// bahtAliases are look-alikes of the baht sign that a lenient decoder reads
+// as U+0E3F: the bitcoin sign and Latin capital B with stroke, which fonts
+// and autocorrection tend to substitute for it.
+var bahtAliases = map[rune]bool{
+	'\u20bf': true, // ₿ BITCOIN SIGN
+	'\u0243': true, // Ƀ LATIN CAPITAL LETTER B WITH STROKE
+}
+
+// digit returns the value of the digit r. Lenient encodings also accept the
+// look-alikes in bahtAliases if their alphabet has the baht sign.
+func (enc *Encoding) digit(r rune) (int, bool) {
+	d, ok := enc.index[r]
+	if !ok && enc.lenient && bahtAliases[r] {
+		d, ok = enc.index[thaiBaht]
 	}
+	return d, ok
+}
 
-	return out, nil
+// isPad returns true if r is one of enc's padding characters.
+func (enc *Encoding) isPad(r rune) bool {
+	if enc.padEnc != nil {
+		return enc.padEnc.IsPad(r)
+	}
+	_, ok := enc.padIndex[r]
+	return ok
 }
@@ -3,8 +3,17 @@ package padthai
 import (
 	"bytes"
 	"crypto/rand"
+	"errors"
 	"io"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 func TestAlphabetSize(t *testing.T) {
@@ -32,6 +41,18 @@ func TestAlphabetUniqueness(t *testing.T) {
 	}
 }
 
+func TestAlphabetStrings(t *testing.T) {
+	if got := []rune(ThaiAlphabetString()); string(got) != string(ThaiAlphabet[:]) {
+		t.Errorf("ThaiAlphabetString() = %q, want runes of ThaiAlphabet", string(got))
+	}
+	if got := []rune(BugineseAlphabetString()); string(got) != string(BugineseAlphabet[:]) {
+		t.Errorf("BugineseAlphabetString() = %q, want runes of BugineseAlphabet", string(got))
+	}
+	if n := utf8.RuneCountInString(ThaiAlphabetString()); n != Base {
+		t.Errorf("ThaiAlphabetString() has %d runes, want %d", n, Base)
+	}
+}
+
 func TestEncodeEmpty(t *testing.T) {
 	encoded := Encode([]byte{})
 	if encoded != "" {
@@ -175,6 +196,55 @@ func TestRoundTripRandomData(t *testing.T) {
 	}
 }
 
+func TestEncodedLen(t *testing.T) {
+	for size := 0; size <= 20; size++ {
+		encoded := Encode(make([]byte, size))
+		if got, want := EncodedRuneLen(size), len([]rune(encoded)); got != want {
+			t.Errorf("EncodedRuneLen(%d) = %d, want %d", size, got, want)
+		}
+		if got, want := EncodedByteLen(size), len(encoded); got != want {
+			t.Errorf("EncodedByteLen(%d) = %d, want %d", size, got, want)
+		}
+	}
+}
+
+func TestEncodedLenOverflow(t *testing.T) {
+	for _, n := range []int{-1, math.MaxInt, math.MaxInt/4 + 1} {
+		if got := EncodedByteLen(n); got != -1 {
+			t.Errorf("EncodedByteLen(%d) = %d, want -1", n, got)
+		}
+	}
+
+	// 4.5× expansion of MaxInt/5 still fits
+	if got := EncodedByteLen(math.MaxInt / 5); got <= 0 {
+		t.Errorf("EncodedByteLen(MaxInt/5) = %d, want a positive length", got)
+	}
+}
+
+func TestEstimateDecodedSize(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 3, 4, 99, 100} {
+		encoded := Encode(make([]byte, size))
+		wrapped := " " + wrapLines(encoded, 7) + "\r\n\t"
+		if got := EstimateDecodedSize(wrapped); got != size {
+			t.Errorf("size %d: EstimateDecodedSize = %d", size, got)
+		}
+	}
+}
+
+func TestDecodedLenBounds(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 3, 4, 99, 100} {
+		wrapped := wrapLines(Encode(make([]byte, size)), 7) + "\n"
+		min, max, err := DecodedLenBounds(wrapped)
+		if err != nil || min != size || max != size {
+			t.Errorf("size %d: DecodedLenBounds = (%d, %d, %v)", size, min, max, err)
+		}
+	}
+
+	if _, _, err := DecodedLenBounds(Encode([]byte{1, 2, 3, 4})[:12] + " \n"); err == nil {
+		t.Error("expected error for 4 runes, got nil")
+	}
+}
+
 func TestEncodedOutputIsValidUTF8(t *testing.T) {
 	input := make([]byte, 137)
 	_, _ = io.ReadFull(rand.Reader, input)
@@ -212,6 +282,63 @@ func TestEncodedCharsInAlphabet(t *testing.T) {
 	}
 }
 
+// TestNormalizationStable guards the normalization forms documented on
+// Encode: the output of every built-in encoding is in NFC, and that of the
+// Thai ones is in NFD too, while CompactEncoding has Й and й, which decompose.
+func TestNormalizationStable(t *testing.T) {
+	for name, tt := range map[string]struct {
+		enc *Encoding
+		nfd bool
+	}{
+		"std":     {StdEncoding, true},
+		"nobaht":  {NoBahtEncoding, true},
+		"compact": {CompactEncoding, false},
+	} {
+		var runes []rune
+		runes = append(runes, tt.enc.alphabet...)
+		runes = append(runes, tt.enc.pad[:]...)
+		runes = append(runes, []rune(bugineseTerminator)...)
+		// Every rune next to every other, as encodings may place them
+		var sb strings.Builder
+		for _, a := range runes {
+			for _, b := range runes {
+				sb.WriteRune(a)
+				sb.WriteRune(b)
+			}
+		}
+		all := sb.String()
+		if !norm.NFC.IsNormalString(all) {
+			t.Errorf("%s: output is not in NFC", name)
+		}
+		if got := norm.NFD.IsNormalString(all); got != tt.nfd {
+			t.Errorf("%s: output in NFD is %v, want %v", name, got, tt.nfd)
+		}
+	}
+}
+
+func TestSaraAmUnaffected(t *testing.T) {
+	// SARA AA, SARA AM, the tone marks and NIKHAHIT: the runes Thai input
+	// methods compose and reorder
+	quirky := []rune{'\u0e32', '\u0e33', '\u0e48', '\u0e49', '\u0e4a', '\u0e4b', '\u0e4d'}
+	for _, enc := range []*Encoding{StdEncoding, NoBahtEncoding} {
+		for _, r := range quirky {
+			if _, ok := enc.digit(r); ok || enc.isPad(r) {
+				t.Errorf("%U is a rune of the encoding", r)
+			}
+		}
+	}
+
+	// Each spelling of a syllable with SARA AM is an error, never data
+	encoded := Encode([]byte("Hello, World!"))
+	for _, spelling := range []string{"\u0e33", "\u0e4d\u0e32", "\u0e48\u0e33", "\u0e4d\u0e48\u0e32"} {
+		for _, s := range []string{encoded[:9] + spelling + encoded[9:], "กก" + spelling} {
+			if data, err := Decode(s); err == nil {
+				t.Errorf("Decode(%q) = %x, want an error", s, data)
+			}
+		}
+	}
+}
+
 func TestDecodeInvalidCharacter(t *testing.T) {
 	_, err := Decode("ABC")
 	if err == nil {
@@ -233,6 +360,37 @@ func TestDecodeTruncatedThaiGroup(t *testing.T) {
 	}
 }
 
+func TestDecodeInvalidLength(t *testing.T) {
+	// A non-canonical first triplet would fail decoding; a length no
+	// encoding has is rejected before it is reached
+	for _, s := range []string{"ฯฯฯก", "ฯฯฯ กกก\n ก", "ฯฯฯ\u00adก", "กกกก\r\n"} {
+		if _, err := Decode(s); !errors.Is(err, ErrInvalidLength) {
+			t.Errorf("Decode(%q): got %v, want ErrInvalidLength", s, err)
+		}
+	}
+
+	// Skipped runes do not count, so valid input heavy with them decodes
+	input := []byte("Hello, World!")
+	spaced := strings.Join(strings.Split(Encode(input), ""), " \u00ad\r\n")
+	for _, enc := range []*Encoding{StdEncoding, CompactEncoding, StdEncoding.Lenient()} {
+		s := strings.Join(strings.Split(enc.Encode(input), ""), "\t \u00ad\n")
+		if got, err := enc.Decode(s); err != nil || !bytes.Equal(got, input) {
+			t.Errorf("%q: got %q, %v", s, got, err)
+		}
+	}
+	if got, err := StdEncoding.Lenient().Decode(spaced + "\ufe0f"); err != nil || !bytes.Equal(got, input) {
+		t.Errorf("with variation selector: got %q, %v", got, err)
+	}
+
+	// Errors found by the full decode keep their precedence
+	if _, err := Decode(Encode(input)[:len(Encode(input))-3]); !errors.Is(err, ErrTruncatedPadding) {
+		t.Errorf("lone padding: got %v, want ErrTruncatedPadding", err)
+	}
+	if _, err := Decode("กกก\ufe0f"); !errors.Is(err, ErrVariationSelector) {
+		t.Errorf("variation selector: got %v, want ErrVariationSelector", err)
+	}
+}
+
 func TestDecodeSingleBuginese(t *testing.T) {
 	// A single Buginese char is invalid (need exactly 0 or 2 for padding)
 	single := string(BugineseAlphabet[0])
@@ -242,6 +400,58 @@ func TestDecodeSingleBuginese(t *testing.T) {
 	}
 }
 
+func TestDecodeTruncatedPadding(t *testing.T) {
+	encoded := []rune(Encode([]byte{0x12, 0x34, 0x56}))
+	truncated := string(encoded[:4]) // 3 Thai + first of 2 Buginese
+
+	_, err := Decode(truncated)
+	if !errors.Is(err, ErrTruncatedPadding) {
+		t.Fatalf("expected ErrTruncatedPadding, got %v", err)
+	}
+	for _, want := range []string{"position 3", "one more padding character would complete it"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+
+	// With a Thai digit missing as well, one more rune is not enough
+	_, err = Decode(string(encoded[:2]) + string(encoded[3]))
+	if !errors.Is(err, ErrTruncatedPadding) {
+		t.Fatalf("expected ErrTruncatedPadding, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "preceding digits are incomplete") {
+		t.Errorf("error %q does not flag the incomplete digits", err)
+	}
+}
+
+func TestDecodeInvalidPadding(t *testing.T) {
+	pair := Encode([]byte{0x21})
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{Encode([]byte("Hi")) + pair + string(BugineseAlphabet[3]), "3 padding characters at position 3"},
+		{pair + pair, "4 padding characters at position 0"},
+		{pair + string(ThaiAlphabet[0]), "padding character U+1A02 at position 0 is not at the end"},
+	}
+	for _, tt := range tests {
+		_, err := Decode(tt.input)
+		if !errors.Is(err, ErrInvalidPadding) {
+			t.Errorf("%q: expected ErrInvalidPadding, got %v", tt.input, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: error %q does not contain %q", tt.input, err, tt.want)
+		}
+
+		// The streaming decoder agrees
+		_, serr := io.ReadAll(NewDecoder(strings.NewReader(tt.input)))
+		if serr == nil || serr.Error() != err.Error() {
+			t.Errorf("%q: streaming error %v, want %v", tt.input, serr, err)
+		}
+	}
+}
+
 func TestDecodeWhitespaceSkipped(t *testing.T) {
 	input := []byte{0xDE, 0xAD, 0xBE, 0xEF}
 	encoded := Encode(input)
@@ -266,6 +476,111 @@ func TestDecodeWhitespaceSkipped(t *testing.T) {
 	}
 }
 
+func TestDecodeStrict(t *testing.T) {
+	decoded, err := DecodeStrict("")
+	if err != nil || decoded == nil || len(decoded) != 0 {
+		t.Errorf("empty input: got %v, %v; want an empty slice", decoded, err)
+	}
+
+	for _, blank := range []string{"   ", "\r\n", "\t\u00ad"} {
+		if _, err := DecodeStrict(blank); !errors.Is(err, ErrNoContent) {
+			t.Errorf("%q: expected ErrNoContent, got %v", blank, err)
+		}
+		// Decode itself is unchanged
+		if decoded, err := Decode(blank); err != nil || len(decoded) != 0 {
+			t.Errorf("Decode(%q) = %v, %v", blank, decoded, err)
+		}
+	}
+
+	encoded := " " + Encode([]byte("Hi")) + "\n"
+	if decoded, err := DecodeStrict(encoded); err != nil || string(decoded) != "Hi" {
+		t.Errorf("normal input: got %q, %v", decoded, err)
+	}
+	if _, err := DecodeStrict("ABC"); err == nil || errors.Is(err, ErrNoContent) {
+		t.Errorf("invalid input: got %v, want a decode error", err)
+	}
+}
+
+func TestDecodeMeta(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 3, 100, 101} {
+		input := make([]byte, size)
+		_, _ = io.ReadFull(rand.Reader, input)
+		encoded := Encode(input)
+
+		data, hadPadding, err := DecodeMeta(encoded)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(data, input) {
+			t.Errorf("size %d: roundtrip mismatch", size)
+		}
+		last, _ := utf8.DecodeLastRuneInString(encoded)
+		if want := size%2 == 1; hadPadding != want || isBuginese(last) != want {
+			t.Errorf("size %d: hadPadding = %v, want %v", size, hadPadding, want)
+		}
+	}
+
+	if _, hadPadding, err := DecodeMeta("ABC"); err == nil || hadPadding {
+		t.Errorf("invalid input: got %v, %v", hadPadding, err)
+	}
+}
+
+func TestDecodeSoftHyphen(t *testing.T) {
+	input := make([]byte, 101)
+	_, _ = io.ReadFull(rand.Reader, input)
+
+	// Soft hyphens at every wrap point, as a hyphenating wrapper inserts them
+	hyphenated := strings.ReplaceAll(wrapLines(Encode(input), 20), "\n", "\u00ad\n")
+	decoded, err := Decode(hyphenated)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("roundtrip mismatch")
+	}
+
+	decoded, err = io.ReadAll(NewDecoder(strings.NewReader(hyphenated)))
+	if err != nil || !bytes.Equal(decoded, input) {
+		t.Errorf("streaming: got (%x, %v), want the input", decoded, err)
+	}
+
+	// No encoding rune can be a soft hyphen
+	for _, enc := range []*Encoding{StdEncoding, CompactEncoding} {
+		if _, ok := enc.digit(softHyphen); ok || enc.isPad(softHyphen) {
+			t.Errorf("soft hyphen is an encoding rune")
+		}
+	}
+	main, pad := latinAlphabets()
+	main[0] = softHyphen
+	if _, err := NewEncoding(main, pad); !errors.Is(err, ErrAlphabetConflict) {
+		t.Errorf("NewEncoding with a soft hyphen: expected ErrAlphabetConflict, got %v", err)
+	}
+}
+
+func TestDecodeVariationSelector(t *testing.T) {
+	input := []byte{0xDE, 0xAD, 0xBE, 0xEF}
+	runes := []rune(Encode(input))
+
+	// Insert U+FE0F after the fourth character, as an emoji keyboard might
+	withVS := string(runes[:4]) + "\ufe0f" + string(runes[4:])
+
+	_, err := Decode(withVS)
+	if !errors.Is(err, ErrVariationSelector) {
+		t.Fatalf("strict decode: expected ErrVariationSelector, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "U+FE0F at position 4") {
+		t.Errorf("error does not locate the selector: %v", err)
+	}
+
+	decoded, err := StdEncoding.Lenient().Decode(withVS)
+	if err != nil {
+		t.Fatalf("lenient decode: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("lenient roundtrip mismatch: got %x, want %x", decoded, input)
+	}
+}
+
 func TestRoundTripAllZeros(t *testing.T) {
 	for _, size := range []int{1, 2, 3, 4, 100} {
 		input := make([]byte, size)
@@ -347,6 +662,24 @@ func TestMaxUint16Value(t *testing.T) {
 	}
 }
 
+func TestDecodeNonCanonical(t *testing.T) {
+	// d0 = 47 gives 47*48² = 108288, well above 0xFFFF
+	triplet := string([]rune{ThaiAlphabet[47], ThaiAlphabet[0], ThaiAlphabet[1]})
+	_, err := Decode(Encode([]byte{1, 2}) + triplet)
+	if !errors.Is(err, ErrNonCanonical) {
+		t.Fatalf("expected ErrNonCanonical, got %v", err)
+	}
+	want := "triplet " + triplet + " at position 3 has digits [47 0 1], decoding to 108289, above 0xFFFF"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("error %q does not contain %q", err, want)
+	}
+
+	// Word groups report the same error
+	if _, err := StdEncoding.WithGroupSize(4).Decode(strings.Repeat(string(ThaiAlphabet[47]), 6)); !errors.Is(err, ErrNonCanonical) {
+		t.Errorf("word group: expected ErrNonCanonical, got %v", err)
+	}
+}
+
 func BenchmarkEncode(b *testing.B) {
 	input := make([]byte, 4096)
 	_, _ = io.ReadFull(rand.Reader, input)
@@ -358,6 +691,38 @@ func BenchmarkEncode(b *testing.B) {
 	}
 }
 
+func TestEncodeDirect(t *testing.T) {
+	input := make([]byte, 101)
+	_, _ = io.ReadFull(rand.Reader, input)
+	for _, enc := range []*Encoding{StdEncoding, CompactEncoding, NoBahtEncoding, StdEncoding.ReverseDigits(), StdEncoding.WithExplicitTerminator().Clone()} {
+		for _, size := range []int{17, 100, 101} {
+			var sb strings.Builder
+			encodeTo(enc, &sb, input[:size])
+			sb.WriteString(enc.term)
+			if got, want := enc.Encode(input[:size]), sb.String(); got != want {
+				t.Errorf("size %d: Encode = %q, encodeTo wrote %q", size, got, want)
+			}
+		}
+	}
+}
+
+// BenchmarkEncodeWriteRune encodes like BenchmarkEncode through encodeTo,
+// which writes rune by rune through a runeWriter, as Encode did before it
+// appended pre-encoded runes, for comparison.
+func BenchmarkEncodeWriteRune(b *testing.B) {
+	input := make([]byte, 4096)
+	_, _ = io.ReadFull(rand.Reader, input)
+
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var sb strings.Builder
+		sb.Grow(EncodedByteLen(len(input)))
+		encodeTo(StdEncoding, &sb, input)
+		_ = sb.String()
+	}
+}
+
 func BenchmarkDecode(b *testing.B) {
 	input := make([]byte, 4096)
 	_, _ = io.ReadFull(rand.Reader, input)
@@ -370,3 +735,887 @@ func BenchmarkDecode(b *testing.B) {
 		_, _ = Decode(encoded)
 	}
 }
+
+func TestEncodeTiny(t *testing.T) {
+	main, pad := latinAlphabets()
+	latin, err := NewEncoding(main, pad)
+	if err != nil {
+		t.Fatalf("NewEncoding: %v", err)
+	}
+	input := make([]byte, tinyInput+1)
+	_, _ = io.ReadFull(rand.Reader, input)
+
+	for _, enc := range []*Encoding{StdEncoding, CompactEncoding, latin, StdEncoding.ReverseDigits(), StdEncoding.WithExplicitTerminator()} {
+		for n := 0; n <= len(input); n++ {
+			var sb strings.Builder
+			encodeTo(enc, &sb, input[:n])
+			sb.WriteString(enc.term)
+			if got := enc.Encode(input[:n]); got != sb.String() {
+				t.Errorf("%d bytes: got %q, want %q", n, got, sb.String())
+			}
+		}
+	}
+
+	// The result is the only allocation, either way
+	encoded := Encode(input[:tinyInput])
+	if n := testing.AllocsPerRun(100, func() { _ = Encode(input[:tinyInput]) }); n > 1 {
+		t.Errorf("Encode: %v allocations", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { _, _ = Decode(encoded) }); n > 1 {
+		t.Errorf("Decode: %v allocations", n)
+	}
+}
+
+func BenchmarkDecode1MiB(b *testing.B) {
+	input := make([]byte, 1<<20)
+	_, _ = io.ReadFull(rand.Reader, input)
+	encoded := wrapLines(Encode(input), 76)
+
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Decode(encoded)
+	}
+}
+
+func BenchmarkEncodeTiny(b *testing.B) {
+	input := []byte{0xbe, 0xef}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Encode(input)
+	}
+}
+
+func BenchmarkDecodeTiny(b *testing.B) {
+	encoded := Encode([]byte{0xbe, 0xef})
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = Decode(encoded)
+	}
+}
+
+// latinAlphabets returns a valid 48-rune ASCII main alphabet and a 16-rune
+// padding alphabet for exercising custom encodings.
+func latinAlphabets() (main, pad []rune) {
+	main = []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuv")
+	pad = []rune("0123456789!#$%&*")
+	return main, pad
+}
+
+func TestNewEncodingRoundTrip(t *testing.T) {
+	main, pad := latinAlphabets()
+	enc, err := NewEncoding(main, pad)
+	if err != nil {
+		t.Fatalf("NewEncoding: %v", err)
+	}
+
+	input := []byte("custom alphabets!")
+	encoded := enc.Encode(input)
+	for _, r := range encoded {
+		if r >= 0x80 {
+			t.Fatalf("custom encoding emitted non-ASCII rune %U", r)
+		}
+	}
+	decoded, err := enc.Decode(encoded)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("roundtrip mismatch: got %q, want %q", decoded, input)
+	}
+}
+
+func TestNewEncodingInvalidAlphabet(t *testing.T) {
+	main, pad := latinAlphabets()
+
+	withSpace := append([]rune(nil), main...)
+	withSpace[10] = ' '
+
+	withSurrogate := append([]rune(nil), main...)
+	withSurrogate[10] = 0xD800
+
+	withControl := append([]rune(nil), pad...)
+	withControl[3] = '\x07'
+
+	duplicate := append([]rune(nil), pad...)
+	duplicate[0] = main[0]
+
+	testCases := []struct {
+		name      string
+		main, pad []rune
+		want      error
+		wantText  string
+	}{
+		{"space", withSpace, pad, ErrAlphabetConflict, "U+0020"},
+		{"surrogate", withSurrogate, pad, ErrAlphabetConflict, "U+D800"},
+		{"control", main, withControl, ErrAlphabetConflict, "U+0007"},
+		{"duplicate", main, duplicate, ErrAlphabetConflict, "U+0041"},
+		{"short main", main[:40], pad, ErrAlphabetSize, "40 runes"},
+		{"short pad", main, pad[:15], ErrAlphabetSize, "15 runes"},
+	}
+
+	for _, tc := range testCases {
+		_, err := NewEncoding(tc.main, tc.pad)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.wantText) {
+			t.Errorf("%s: error %q does not mention %q", tc.name, err, tc.wantText)
+		}
+	}
+}
+
+func TestWordGroupRoundTrip(t *testing.T) {
+	enc := StdEncoding.WithGroupSize(4)
+
+	for size := 0; size <= 13; size++ {
+		input := make([]byte, size)
+		_, _ = io.ReadFull(rand.Reader, input)
+
+		encoded := enc.Encode(input)
+		if got, want := len([]rune(encoded)), EncodedRuneLen(size); got != want {
+			t.Errorf("size %d: expected %d runes, got %d", size, want, got)
+		}
+
+		decoded, err := enc.Decode(encoded)
+		if err != nil {
+			t.Fatalf("size %d: decode: %v", size, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Errorf("size %d: roundtrip mismatch: got %x, want %x", size, decoded, input)
+		}
+	}
+}
+
+func TestWordGroupDigits(t *testing.T) {
+	enc := StdEncoding.WithGroupSize(4)
+
+	// 0x00000001 is a single word: five zero digits then a one
+	encoded := []rune(enc.Encode([]byte{0x00, 0x00, 0x00, 0x01}))
+	want := []rune{ThaiAlphabet[0], ThaiAlphabet[0], ThaiAlphabet[0], ThaiAlphabet[0], ThaiAlphabet[0], ThaiAlphabet[1]}
+	if string(encoded) != string(want) {
+		t.Errorf("got %q, want %q", string(encoded), string(want))
+	}
+
+	// A 6-digit group above 2^32-1 must be rejected
+	overflow := strings.Repeat(string(ThaiAlphabet[Base-1]), 6)
+	if _, err := enc.Decode(overflow); err == nil {
+		t.Error("expected error for out-of-range word, got nil")
+	}
+}
+
+func TestNewEncodingFromString(t *testing.T) {
+	main, pad := latinAlphabets()
+	enc, err := NewEncodingFromString(string(main), string(pad))
+	if err != nil {
+		t.Fatalf("NewEncodingFromString: %v", err)
+	}
+	input := []byte{0x00, 0xFF, 0x7F}
+	decoded, err := enc.Decode(enc.Encode(input))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("roundtrip mismatch: got %x, want %x", decoded, input)
+	}
+
+	if _, err := NewEncodingFromString(string(main[:40]), string(pad)); !errors.Is(err, ErrAlphabetSize) {
+		t.Errorf("short main alphabet: expected ErrAlphabetSize, got %v", err)
+	}
+	if _, err := NewEncodingFromString(string(main), "0123456789 #$%&*"); !errors.Is(err, ErrAlphabetConflict) {
+		t.Errorf("whitespace in padding: expected ErrAlphabetConflict, got %v", err)
+	}
+}
+
+// TestConcurrentUse hammers shared encodings from many goroutines. Run it
+// with -race to check that encoding and decoding do not mutate shared state.
+func TestConcurrentUse(t *testing.T) {
+	encodings := []*Encoding{StdEncoding, StdEncoding.Lenient(), StdEncoding.WithGroupSize(4)}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for g := 0; g < 32; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			enc := encodings[g%len(encodings)]
+			input := make([]byte, 64+g)
+			for i := range input {
+				input[i] = byte(g * i)
+			}
+			for i := 0; i < 200; i++ {
+				decoded, err := enc.Decode(enc.Encode(input))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if !bytes.Equal(decoded, input) {
+					errs <- errors.New("roundtrip mismatch")
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestWithSkipFunc(t *testing.T) {
+	input := []byte("separated by bars")
+	runes := []rune(Encode(input))
+
+	// Separate triplets with a visual bar
+	var sb strings.Builder
+	for i, r := range runes {
+		if i > 0 && i%3 == 0 {
+			sb.WriteString(" | ")
+		}
+		sb.WriteRune(r)
+	}
+	barred := sb.String()
+
+	if _, err := Decode(barred); err == nil {
+		t.Fatal("default encoding accepted '|', expected an error")
+	}
+
+	enc := StdEncoding.WithSkipFunc(func(r rune) bool {
+		return r == '|' || r == ' '
+	})
+
+	decoded, err := enc.Decode(barred)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("decode mismatch: got %q, want %q", decoded, input)
+	}
+
+	streamed, err := io.ReadAll(enc.NewDecoder(strings.NewReader(barred)))
+	if err != nil {
+		t.Fatalf("streaming decode: %v", err)
+	}
+	if !bytes.Equal(streamed, input) {
+		t.Errorf("streaming decode mismatch: got %q, want %q", streamed, input)
+	}
+}
+
+func TestSkips(t *testing.T) {
+	bars := StdEncoding.WithSkipFunc(func(r rune) bool { return r == '|' })
+	for _, tt := range []struct {
+		enc  *Encoding
+		r    rune
+		want bool
+	}{
+		{StdEncoding, ' ', true},
+		{StdEncoding, '\u00ad', true},
+		{StdEncoding, 'ก', false},
+		{StdEncoding, '\ufe0f', false},
+		{StdEncoding.Lenient(), '\ufe0f', true},
+		{bars, '|', true},
+		{bars, ' ', false},
+	} {
+		if got := tt.enc.Skips(tt.r); got != tt.want {
+			t.Errorf("Skips(%U) = %v, want %v", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestExplicitTerminator(t *testing.T) {
+	enc := StdEncoding.WithExplicitTerminator()
+
+	records := [][]byte{{0xDE, 0xAD, 0xBE, 0xEF}, {0x01, 0x02}, {0x03}, {}}
+	var stream strings.Builder
+	for _, rec := range records {
+		encoded := enc.Encode(rec)
+		if !strings.HasSuffix(encoded, "᨞᨟") {
+			t.Errorf("%x: encoding %q lacks the terminator", rec, encoded)
+		}
+		if got, want := utf8.RuneCountInString(encoded), EncodedRuneLen(len(rec))+2; got != want {
+			t.Errorf("%x: expected %d runes, got %d", rec, want, got)
+		}
+
+		decoded, err := enc.Decode(encoded)
+		if err != nil {
+			t.Fatalf("%x: decode: %v", rec, err)
+		}
+		if !bytes.Equal(decoded, rec) {
+			t.Errorf("roundtrip mismatch: got %x, want %x", decoded, rec)
+		}
+		stream.WriteString(encoded)
+	}
+
+	// Concatenated records can be split at the terminators
+	parts := strings.SplitAfter(stream.String(), "᨞᨟")
+	for i, rec := range records {
+		decoded, err := enc.Decode(parts[i])
+		if err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if !bytes.Equal(decoded, rec) {
+			t.Errorf("record %d: got %x, want %x", i, decoded, rec)
+		}
+	}
+
+	for _, missing := range []string{"", Encode([]byte{0x01, 0x02})} {
+		if _, err := enc.Decode(missing); !errors.Is(err, ErrMissingTerminator) {
+			t.Errorf("%q: expected ErrMissingTerminator, got %v", missing, err)
+		}
+		if _, err := io.ReadAll(enc.NewDecoder(strings.NewReader(missing))); !errors.Is(err, ErrMissingTerminator) {
+			t.Errorf("%q: streaming: expected ErrMissingTerminator, got %v", missing, err)
+		}
+	}
+}
+
+func TestEncodingInfo(t *testing.T) {
+	want := EncodingInfo{Base: 48, PadBase: 16, RunesPerByte: 1.5, UTF8BytesPerByte: 4.5}
+	if got := StdEncoding.Info(); got != want {
+		t.Errorf("StdEncoding.Info() = %+v, want %+v", got, want)
+	}
+
+	main, pad := latinAlphabets()
+	enc, err := NewEncoding(main, pad)
+	if err != nil {
+		t.Fatalf("NewEncoding: %v", err)
+	}
+	if got := enc.Info().UTF8BytesPerByte; got != 1.5 {
+		t.Errorf("ASCII alphabet UTF8BytesPerByte = %v, want 1.5", got)
+	}
+}
+
+// BenchmarkDecodeWrapped decodes input wrapped at 76 columns, as produced by
+// most tools, to measure the whitespace-skipping path.
+func BenchmarkDecodeWrapped(b *testing.B) {
+	input := make([]byte, 4096)
+	_, _ = io.ReadFull(rand.Reader, input)
+
+	encoded := wrapLines(Encode(input), 76)
+
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Decode(encoded)
+	}
+}
+
+func TestMixedTailRoundTrip(t *testing.T) {
+	for _, group := range []int{2, 4} {
+		enc := StdEncoding.WithGroupSize(group).WithMixedTail()
+
+		// Cover every tail length mod the group size, twice over
+		for size := 0; size <= 2*group+1; size++ {
+			input := make([]byte, size)
+			_, _ = io.ReadFull(rand.Reader, input)
+
+			encoded := enc.Encode(input)
+			if got, want := len([]rune(encoded)), EncodedRuneLen(size); got != want {
+				t.Errorf("group %d, size %d: expected %d runes, got %d", group, size, want, got)
+			}
+			pads := 0
+			for _, r := range encoded {
+				if isBuginese(r) {
+					pads++
+				}
+			}
+			if pads != size%2 {
+				t.Errorf("group %d, size %d: %d padding runes, want %d", group, size, pads, size%2)
+			}
+
+			decoded, err := enc.Decode(encoded)
+			if err != nil {
+				t.Fatalf("group %d, size %d: decode: %v", group, size, err)
+			}
+			if !bytes.Equal(decoded, input) {
+				t.Errorf("group %d, size %d: roundtrip mismatch: got %x, want %x", group, size, decoded, input)
+			}
+		}
+	}
+}
+
+func TestMixedTailInvalid(t *testing.T) {
+	enc := StdEncoding.WithMixedTail()
+
+	// 0xFF is digit 15, padding 5; padding 6 overflows a byte
+	if got, want := enc.Encode([]byte{0xFF}), string([]rune{ThaiAlphabet[15], BugineseAlphabet[5]}); got != want {
+		t.Errorf("Encode(ff) = %q, want %q", got, want)
+	}
+	for _, encoded := range []string{
+		string([]rune{ThaiAlphabet[0], BugineseAlphabet[6]}), // 288 > 255
+		Encode([]byte{0xFF}),        // standard two-padding tail
+		string(BugineseAlphabet[0]), // no digit
+	} {
+		if _, err := enc.Decode(encoded); err == nil {
+			t.Errorf("Decode(%q): expected error, got nil", encoded)
+		}
+	}
+	// Nor does the standard scheme accept a mixed tail
+	if _, err := Decode(enc.Encode([]byte("odd"))); err == nil {
+		t.Error("Decode of a mixed tail: expected error, got nil")
+	}
+}
+
+func TestZeroPadTrailerRoundTrip(t *testing.T) {
+	for _, group := range []int{2, 4} {
+		enc := StdEncoding.WithGroupSize(group).WithZeroPadTrailer()
+
+		for size := 0; size <= 2*group+1; size++ {
+			input := make([]byte, size)
+			_, _ = io.ReadFull(rand.Reader, input)
+
+			encoded := enc.Encode(input)
+			want := EncodedRuneLen(size)
+			if size%2 == 1 {
+				want += 2
+			}
+			if got := len([]rune(encoded)); got != want {
+				t.Errorf("group %d, size %d: expected %d runes, got %d", group, size, want, got)
+			}
+			for _, r := range encoded {
+				if !isThai(r) {
+					t.Errorf("group %d, size %d: non-Thai rune %U in output", group, size, r)
+				}
+			}
+
+			decoded, err := enc.Decode(encoded)
+			if err != nil {
+				t.Fatalf("group %d, size %d: decode: %v", group, size, err)
+			}
+			if !bytes.Equal(decoded, input) {
+				t.Errorf("group %d, size %d: roundtrip mismatch: got %x, want %x", group, size, decoded, input)
+			}
+		}
+	}
+}
+
+func TestThaiTailRoundTrip(t *testing.T) {
+	for _, group := range []int{2, 4} {
+		enc := StdEncoding.WithGroupSize(group).WithThaiTail()
+
+		for size := 0; size <= 2*group+1; size++ {
+			input := make([]byte, size)
+			_, _ = io.ReadFull(rand.Reader, input)
+
+			encoded := enc.Encode(input)
+			if got, want := len([]rune(encoded)), EncodedRuneLen(size); got != want {
+				t.Errorf("group %d, size %d: expected %d runes, got %d", group, size, want, got)
+			}
+			if got := enc.EncodedLen(size); got != len(encoded) {
+				t.Errorf("group %d, size %d: EncodedLen = %d, want %d", group, size, got, len(encoded))
+			}
+			for _, r := range encoded {
+				if !isThai(r) {
+					t.Errorf("group %d, size %d: non-Thai rune %U in output", group, size, r)
+				}
+			}
+
+			decoded, err := enc.Decode(encoded)
+			if err != nil {
+				t.Fatalf("group %d, size %d: decode: %v", group, size, err)
+			}
+			if !bytes.Equal(decoded, input) {
+				t.Errorf("group %d, size %d: roundtrip mismatch: got %x, want %x", group, size, decoded, input)
+			}
+			streamed, err := io.ReadAll(enc.NewDecoder(strings.NewReader(encoded)))
+			if err != nil || !bytes.Equal(streamed, input) {
+				t.Errorf("group %d, size %d: stream decode: got %x, %v", group, size, streamed, err)
+			}
+		}
+	}
+
+	enc := StdEncoding.WithThaiTail()
+	if got, want := enc.Encode([]byte{0xFF}), string([]rune{ThaiAlphabet[5], ThaiAlphabet[15]}); got != want {
+		t.Errorf("Encode(ff) = %q, want %q", got, want)
+	}
+	for _, encoded := range []string{
+		string([]rune{ThaiAlphabet[5], ThaiAlphabet[16]}), // 256 > 255
+		Encode([]byte{0xFF}),                              // standard padding tail
+		string(ThaiAlphabet[0]),                           // a lone digit
+		Encode([]byte{1, 2, 3, 4})[:12],                   // a truncated triplet
+	} {
+		if _, err := enc.Decode(encoded); err == nil {
+			t.Errorf("Decode(%q): expected error, got nil", encoded)
+		}
+	}
+}
+
+func TestZeroPadTrailerGenuineZero(t *testing.T) {
+	enc := StdEncoding.WithZeroPadTrailer()
+
+	// An even input ending in zero has no marker, so nothing is stripped
+	for _, input := range [][]byte{{0x41, 0x00}, {0x00, 0x00}, {0x41, 0x00, 0x00}} {
+		decoded, err := enc.Decode(enc.Encode(input))
+		if err != nil {
+			t.Fatalf("%x: decode: %v", input, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Errorf("%x: roundtrip mismatch: got %x", input, decoded)
+		}
+	}
+
+	// A marker after a pair whose low byte is not zero is invalid
+	marked := Encode([]byte{0x41, 0x42}) + string(ThaiAlphabet[0])
+	if _, err := enc.Decode(marked); err == nil {
+		t.Error("expected error for non-zero trailer byte, got nil")
+	}
+	if _, err := enc.Decode(string(ThaiAlphabet[0])); err == nil {
+		t.Error("expected error for lone marker, got nil")
+	}
+}
+
+func TestDecodeMostlyWhitespace(t *testing.T) {
+	spaces := strings.Repeat(" ", 512*1024)
+	encoded := spaces + Encode([]byte{0x42}) + spaces
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	decoded, err := Decode(encoded)
+	runtime.ReadMemStats(&after)
+
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !bytes.Equal(decoded, []byte{0x42}) {
+		t.Errorf("got %x, want 42", decoded)
+	}
+	// Sizing by total rune count would allocate 4 MiB of runes
+	if n := after.TotalAlloc - before.TotalAlloc; n > 64*1024 {
+		t.Errorf("Decode allocated %d bytes for 2 runes of data", n)
+	}
+}
+
+func TestDecodeInvalidUTF8(t *testing.T) {
+	encoded := Encode([]byte{0xDE, 0xAD, 0xBE, 0xEF})
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{encoded[:9] + "\x80" + encoded[9:], "byte 0x80 at offset 9"},
+		{encoded + "\xff", "byte 0xff at offset 18"},
+		{encoded[:17], "byte 0xe0 at offset 15"}, // truncated last rune
+	}
+	for _, tt := range tests {
+		_, err := Decode(tt.input)
+		if !errors.Is(err, ErrInvalidUTF8) {
+			t.Errorf("%q: expected ErrInvalidUTF8, got %v", tt.input, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: error %q does not contain %q", tt.input, err, tt.want)
+		}
+	}
+
+	// A genuine U+FFFD is valid UTF-8, just not a digit
+	if _, err := Decode("�"); err == nil || errors.Is(err, ErrInvalidUTF8) {
+		t.Errorf("U+FFFD: expected invalid character error, got %v", err)
+	}
+}
+
+func TestDecodeTruncatedUTF8(t *testing.T) {
+	// Cut 1 or 2 bytes off the last rune, of the padding or of a triplet
+	for _, input := range [][]byte{{0xDE, 0xAD, 0xBE}, {0xDE, 0xAD, 0xBE, 0xEF}} {
+		encoded := Encode(input)
+		for cut := 1; cut <= 2; cut++ {
+			_, err := Decode(encoded[:len(encoded)-cut])
+			if !errors.Is(err, io.ErrUnexpectedEOF) || !errors.Is(err, ErrInvalidUTF8) {
+				t.Errorf("%x cut by %d bytes: expected io.ErrUnexpectedEOF, got %v", input, cut, err)
+			}
+		}
+	}
+
+	// Corruption, and a bad byte followed by more input, are not truncation
+	encoded := Encode([]byte{0xDE, 0xAD, 0xBE, 0xEF})
+	for _, input := range []string{encoded + "\xff", encoded[:8] + encoded[9:]} {
+		if _, err := Decode(input); !errors.Is(err, ErrInvalidUTF8) || errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%q: got %v, want ErrInvalidUTF8 alone", input, err)
+		}
+	}
+}
+
+func TestCompactEncoding(t *testing.T) {
+	input := make([]byte, 1001)
+	_, _ = io.ReadFull(rand.Reader, input)
+
+	encoded := CompactEncoding.Encode(input)
+	if got, want := utf8.RuneCountInString(encoded), EncodedRuneLen(len(input)); got != want {
+		t.Errorf("expected %d runes, got %d", want, got)
+	}
+	decoded, err := CompactEncoding.Decode(encoded)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("roundtrip mismatch")
+	}
+
+	std := Encode(input)
+	if got, want := len(encoded), len(std)*2/3; got != want {
+		t.Errorf("compact output is %d bytes, want %d", got, want)
+	}
+	t.Logf("%d input bytes: StdEncoding %d bytes, CompactEncoding %d bytes", len(input), len(std), len(encoded))
+
+	if info := CompactEncoding.Info(); info.Base != Base || info.UTF8BytesPerByte != 3 {
+		t.Errorf("Info() = %+v, want base %d and 3 UTF-8 bytes per byte", info, Base)
+	}
+}
+
+func TestNoBahtEncoding(t *testing.T) {
+	// Every byte pair, with every possible digit in every position
+	input := make([]byte, 0, 2<<16)
+	for v := 0; v <= 0xFFFF; v++ {
+		input = append(input, byte(v>>8), byte(v))
+	}
+	encoded := NoBahtEncoding.Encode(input)
+	if strings.ContainsRune(encoded, thaiBaht) {
+		t.Errorf("output contains the baht sign")
+	}
+	decoded, err := NoBahtEncoding.Decode(encoded)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("roundtrip mismatch")
+	}
+
+	// Only the last digit differs from StdEncoding, and the baht sign still
+	// decodes
+	std := Encode(input)
+	if strings.ReplaceAll(std, string(thaiBaht), string(thaiDigitZero)) != encoded {
+		t.Errorf("output differs from StdEncoding beyond the last digit")
+	}
+	if decoded, err := NoBahtEncoding.Decode(std); err != nil || !bytes.Equal(decoded, input) {
+		t.Errorf("StdEncoding output: decode error %v", err)
+	}
+
+	for b := 0; b < 256; b++ {
+		odd := []byte{0xFF, 0xFF, byte(b)}
+		decoded, err := NoBahtEncoding.Decode(NoBahtEncoding.Encode(odd))
+		if err != nil || !bytes.Equal(decoded, odd) {
+			t.Errorf("%x: got %x, %v", odd, decoded, err)
+		}
+	}
+}
+
+func TestDecodeBahtAlias(t *testing.T) {
+	input := []byte{0x00, Base - 1} // digits [0 0 47]
+	encoded := Encode(input)
+	if !strings.ContainsRune(encoded, thaiBaht) {
+		t.Fatalf("%q has no baht sign", encoded)
+	}
+
+	for _, alias := range []rune{'₿', 'Ƀ'} {
+		pasted := strings.ReplaceAll(encoded, string(thaiBaht), string(alias))
+		if _, err := Decode(pasted); err == nil {
+			t.Errorf("%U: strict decode accepted an alias", alias)
+		}
+		decoded, err := StdEncoding.Lenient().Decode(pasted)
+		if err != nil {
+			t.Fatalf("%U: lenient decode: %v", alias, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Errorf("%U: got %x, want %x", alias, decoded, input)
+		}
+	}
+}
+
+func TestReverseDigits(t *testing.T) {
+	enc := StdEncoding.ReverseDigits()
+
+	// 0x0001 is digits [0 0 1]; reversed, the one comes first
+	if got, want := enc.Encode([]byte{0x00, 0x01}), string([]rune{ThaiAlphabet[1], ThaiAlphabet[0], ThaiAlphabet[0]}); got != want {
+		t.Errorf("Encode(0001) = %q, want %q", got, want)
+	}
+
+	input := []byte("Hello, World!")
+	for _, enc := range []*Encoding{enc, enc.WithGroupSize(4), enc.WithZeroPadTrailer()} {
+		encoded := enc.Encode(input)
+		if encoded == Encode(input) {
+			t.Errorf("reversed encoding equals the standard one")
+		}
+		decoded, err := enc.Decode(encoded)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Errorf("roundtrip mismatch: got %q, want %q", decoded, input)
+		}
+	}
+}
+
+func TestWithFold(t *testing.T) {
+	enc, err := NewEncoding([]rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789+-/=.:,"), []rune("!#$%&*()<>?@[]^_"))
+	if err != nil {
+		t.Fatalf("NewEncoding: %v", err)
+	}
+	folding := enc.WithFold(unicode.ToUpper)
+
+	input := []byte("Hello, World!")
+	encoded := folding.Encode(input)
+	if encoded != enc.Encode(input) {
+		t.Errorf("folding changed the encoding")
+	}
+	lower := strings.ToLower(encoded)
+	if lower == encoded {
+		t.Fatalf("test input %q has no letters", encoded)
+	}
+	if _, err := enc.Decode(lower); err == nil {
+		t.Errorf("lower-case input decoded without folding")
+	}
+	for _, dec := range []func(string) ([]byte, error){
+		folding.Decode,
+		func(s string) ([]byte, error) { return folding.DecodeReaderAll(strings.NewReader(s)) },
+	} {
+		decoded, err := dec(lower)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Errorf("roundtrip mismatch: got %q, want %q", decoded, input)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("fold changing alphabet runes did not panic")
+		}
+	}()
+	enc.WithFold(unicode.ToLower)
+}
+
+func TestWithRuneMapper(t *testing.T) {
+	// Legacy input wrote the baht sign as a dollar sign
+	enc := StdEncoding.WithRuneMapper(func(r rune) rune {
+		if r == '$' {
+			return '฿'
+		}
+		return r
+	})
+
+	input := []byte{0x08, 0xFF, 0x12} // 0x08FF is digits 0, 47, 47
+	encoded := Encode(input)
+	legacy := strings.ReplaceAll(encoded, "฿", "$")
+	if legacy == encoded {
+		t.Fatalf("test input %q has no baht sign", encoded)
+	}
+	if _, err := Decode(legacy); err == nil {
+		t.Errorf("legacy input decoded without the mapper")
+	}
+	decoded, err := enc.Decode(legacy)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("roundtrip mismatch: got %x, want %x", decoded, input)
+	}
+	if enc.Encode(input) != encoded {
+		t.Errorf("the mapper changed the encoding")
+	}
+}
+
+func TestDigitOutOfRangePanics(t *testing.T) {
+	// A base larger than the alphabet yields digits with no rune
+	bad := *StdEncoding
+	bad.base = 64
+
+	defer func() {
+		msg, _ := recover().(string)
+		if want := "padthai: digit 63 out of range for base 64"; msg != want {
+			t.Errorf("panic %q, want %q", msg, want)
+		}
+	}()
+	bad.Encode([]byte{0xFF, 0xFF})
+}
+
+func TestClone(t *testing.T) {
+	input := []byte("Hello, World!")
+	want := Encode(input)
+
+	clone := StdEncoding.Clone()
+	if got := clone.Encode(input); got != want {
+		t.Errorf("clone encodes to %q, want %q", got, want)
+	}
+
+	// Neither options on the clone nor changes to its tables reach the source
+	_ = clone.ReverseDigits().WithExplicitTerminator()
+	clone.alphabet[0] = 'X'
+	clone.index['X'] = 0
+	delete(clone.padIndex, BugineseAlphabet[0])
+	if got := Encode(input); got != want {
+		t.Errorf("source encodes to %q after changing the clone, want %q", got, want)
+	}
+	if _, ok := StdEncoding.index['X']; ok || ThaiAlphabet[0] != 'ก' {
+		t.Errorf("clone shares tables with the source")
+	}
+	if decoded, err := Decode(want); err != nil || !bytes.Equal(decoded, input) {
+		t.Errorf("source decode: %v", err)
+	}
+}
+
+func TestTripletDigits(t *testing.T) {
+	for val := uint(0); val <= 0xFFFF; val++ {
+		d0, d1, d2 := StdEncoding.tripletDigits(val)
+		if (d0*Base+d1)*Base+d2 != val || d1 >= Base || d2 >= Base {
+			t.Fatalf("tripletDigits(%d) = [%d %d %d]", val, d0, d1, d2)
+		}
+	}
+}
+
+func TestIsCanonicalTriplet(t *testing.T) {
+	canonical := 0
+	for d0 := 0; d0 < Base; d0++ {
+		for d1 := 0; d1 < Base; d1++ {
+			for d2 := 0; d2 < Base; d2++ {
+				triplet := string([]rune{ThaiAlphabet[d0], ThaiAlphabet[d1], ThaiAlphabet[d2]})
+				_, err := Decode(triplet)
+				if got := IsCanonicalTriplet(d0, d1, d2); got != (err == nil) {
+					t.Fatalf("IsCanonicalTriplet(%d, %d, %d) = %v, but Decode returned %v", d0, d1, d2, got, err)
+				}
+				if err == nil {
+					canonical++
+				}
+			}
+		}
+	}
+	if canonical != MaxTripletValue+1 {
+		t.Errorf("%d canonical triplets, want %d", canonical, MaxTripletValue+1)
+	}
+	if TripletCombinations != 110592 {
+		t.Errorf("TripletCombinations = %d", TripletCombinations)
+	}
+
+	for _, d := range [][3]int{{-1, 0, 0}, {0, Base, 0}, {0, 0, 99}} {
+		if IsCanonicalTriplet(d[0], d[1], d[2]) {
+			t.Errorf("IsCanonicalTriplet(%v) = true for an out-of-range digit", d)
+		}
+	}
+}
+
+// digitSink keeps the compiler from optimizing away benchmarked arithmetic.
+var digitSink uint
+
+func BenchmarkTripletDigits(b *testing.B) {
+	main, pad := latinAlphabets()
+	base47, err := NewEncoding(main[:47], pad)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, bb := range []struct {
+		name string
+		enc  *Encoding
+	}{{"base48", StdEncoding}, {"base47", base47}} {
+		b.Run(bb.name, func(b *testing.B) {
+			var sum uint
+			for i := 0; i < b.N; i++ {
+				d0, d1, d2 := bb.enc.tripletDigits(uint(i) & 0xFFFF)
+				sum += d0 + d1 + d2
+			}
+			digitSink = sum
+		})
+	}
+}
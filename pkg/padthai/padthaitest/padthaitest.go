@@ -0,0 +1,55 @@
+// Package padthaitest provides helpers for testing code that uses padthai.
+package padthaitest
+
+import (
+	"testing"
+
+	"github.com/lynxnot/base-padthai/pkg/padthai"
+)
+
+// AssertRoundTrip encodes data with padthai.Encode, decodes the result with
+// padthai.Decode, and fails t if decoding errors or does not reproduce data.
+func AssertRoundTrip(t testing.TB, data []byte) {
+	t.Helper()
+	assertRoundTrip(t, padthai.Encode, padthai.Decode, data)
+}
+
+// assertRoundTrip is AssertRoundTrip with the codec injected, so that the
+// failure path can be tested.
+func assertRoundTrip(t testing.TB, encode func([]byte) string, decode func(string) ([]byte, error), data []byte) {
+	t.Helper()
+
+	encoded := encode(data)
+	decoded, err := decode(encoded)
+	if err != nil {
+		t.Errorf("padthaitest: decoding the encoding of %d bytes failed: %v", len(data), err)
+		return
+	}
+
+	i := firstDiff(decoded, data)
+	if i < 0 {
+		return
+	}
+	lo, hi := max(i-8, 0), i+8
+	t.Errorf("padthaitest: round trip mismatch at byte %d (got %d bytes, want %d)\n  got:  ...%x...\n  want: ...%x...",
+		i, len(decoded), len(data), clip(decoded, lo, hi), clip(data, lo, hi))
+}
+
+// firstDiff returns the index of the first byte at which a and b differ, or
+// -1 if they are equal.
+func firstDiff(a, b []byte) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	if len(a) != len(b) {
+		return min(len(a), len(b))
+	}
+	return -1
+}
+
+// clip returns b[lo:hi], with both bounds clamped to the length of b.
+func clip(b []byte, lo, hi int) []byte {
+	return b[min(lo, len(b)):min(hi, len(b))]
+}
@@ -0,0 +1,65 @@
+package padthaitest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lynxnot/base-padthai/pkg/padthai"
+)
+
+// fakeTB records failures instead of failing the enclosing test.
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertRoundTrip(t *testing.T) {
+	for _, data := range [][]byte{nil, {0x00}, []byte("hello, padthai")} {
+		AssertRoundTrip(t, data)
+	}
+}
+
+func TestAssertRoundTripMismatch(t *testing.T) {
+	// A decoder that corrupts byte 5
+	corrupt := func(s string) ([]byte, error) {
+		out, err := padthai.Decode(s)
+		if len(out) > 5 {
+			out[5] ^= 0xFF
+		}
+		return out, err
+	}
+
+	fake := &fakeTB{}
+	assertRoundTrip(fake, padthai.Encode, corrupt, []byte("hello, padthai"))
+
+	if len(fake.errors) != 1 {
+		t.Fatalf("expected 1 failure, got %d", len(fake.errors))
+	}
+	if !strings.Contains(fake.errors[0], "mismatch at byte 5") {
+		t.Errorf("unexpected failure message: %q", fake.errors[0])
+	}
+}
+
+func TestFirstDiff(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", -1},
+		{"abc", "abc", -1},
+		{"abc", "abd", 2},
+		{"ab", "abc", 2},
+	}
+	for _, tc := range testCases {
+		if got := firstDiff([]byte(tc.a), []byte(tc.b)); got != tc.want {
+			t.Errorf("firstDiff(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
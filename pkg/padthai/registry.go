@@ -0,0 +1,59 @@
+package padthai
+
+import (
+	"slices"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]*Encoding)
+)
+
+// Register makes an encoding available by name to ByName, for command-line
+// tools and configuration loaders. The built-in encodings are registered as
+// "thai" (StdEncoding), "compact" (CompactEncoding) and "nobaht"
+// (NoBahtEncoding).
+//
+// Register is meant to be called from init functions. It panics if e is nil
+// or if name is already registered. It is safe to call concurrently with
+// ByName and Names.
+func Register(name string, e *Encoding) {
+	if e == nil {
+		panic("padthai: Register encoding is nil")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[name]; dup {
+		panic("padthai: Register called twice for encoding " + name)
+	}
+	registry[name] = e
+}
+
+// unregister removes the encoding registered under name, if any, for tests
+// that register encodings of their own.
+func unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, name)
+}
+
+// ByName returns the encoding registered under name, and whether there is one.
+func ByName(name string) (*Encoding, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	e, ok := registry[name]
+	return e, ok
+}
+
+// Names returns the names of the registered encodings, sorted.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,35 @@
+package padthai
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	if enc, ok := ByName("thai"); !ok || enc != StdEncoding {
+		t.Errorf("ByName(thai) = (%p, %v), want StdEncoding", enc, ok)
+	}
+	if enc, ok := ByName("compact"); !ok || enc != CompactEncoding {
+		t.Errorf("ByName(compact) = (%p, %v), want CompactEncoding", enc, ok)
+	}
+	if _, ok := ByName("klingon"); ok {
+		t.Error("ByName(klingon) found an encoding")
+	}
+
+	custom := StdEncoding.WithGroupSize(4)
+	Register("test-words", custom)
+	t.Cleanup(func() { unregister("test-words") })
+	if enc, ok := ByName("test-words"); !ok || enc != custom {
+		t.Errorf("ByName(test-words) = (%p, %v), want the registered encoding", enc, ok)
+	}
+	if names := Names(); !slices.Contains(names, "test-words") || !slices.IsSorted(names) {
+		t.Errorf("Names() = %q", names)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic registering a name twice")
+		}
+	}()
+	Register("thai", custom)
+}
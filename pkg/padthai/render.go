@@ -0,0 +1,72 @@
+package padthai
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// RenderabilityHint describes the fonts needed to display StdEncoding output:
+// its digits are Thai, and odd-length data ends in Buginese padding, a block
+// many systems have no font for.
+func RenderabilityHint() string {
+	return StdEncoding.RenderabilityHint()
+}
+
+// RenderabilityHint describes the Unicode scripts and ranges a font must
+// cover to display enc's output, for instance for a CLI to warn users that
+// its output may show up as boxes:
+//
+//	requires a font covering Thai (U+0E01–U+0E3F); odd-length data also needs Buginese (U+1A00–U+1A0F)
+//
+// The text is meant for humans; its format may change.
+func (enc *Encoding) RenderabilityHint() string {
+	var sb strings.Builder
+	sb.WriteString("requires a font covering ")
+	sb.WriteString(describeRunes(enc.alphabet))
+
+	var pad []rune
+	switch {
+	case enc.tail == tailZero, enc.tail == tailThai:
+		// The tail is made of digits
+	case enc.padEnc != nil:
+		for b := 0; b < 256; b++ {
+			pad = append(pad, enc.padEnc.Encode(byte(b))...)
+		}
+	default:
+		pad = enc.pad[:]
+	}
+	if len(pad) > 0 {
+		sb.WriteString("; odd-length data also needs ")
+		sb.WriteString(describeRunes(pad))
+	}
+	if enc.term != "" {
+		sb.WriteString("; the terminator needs ")
+		sb.WriteString(describeRunes([]rune(enc.term)))
+	}
+	return sb.String()
+}
+
+// describeRunes names the scripts of runes, followed by the range they span.
+func describeRunes(runes []rune) string {
+	var scripts []string
+	for _, r := range runes {
+		for name, table := range unicode.Scripts {
+			if unicode.Is(table, r) && !slices.Contains(scripts, name) {
+				scripts = append(scripts, name)
+			}
+		}
+	}
+	// Shared punctuation and signs, like the baht sign, only matter alone
+	if len(scripts) > 1 {
+		scripts = slices.DeleteFunc(scripts, func(name string) bool {
+			return name == "Common" || name == "Inherited"
+		})
+	}
+	slices.Sort(scripts)
+	if len(scripts) == 0 {
+		scripts = []string{"unassigned characters"}
+	}
+	return fmt.Sprintf("%s (U+%04X–U+%04X)", strings.Join(scripts, " and "), slices.Min(runes), slices.Max(runes))
+}
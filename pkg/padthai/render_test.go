@@ -0,0 +1,29 @@
+package padthai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderabilityHint(t *testing.T) {
+	hint := RenderabilityHint()
+	for _, want := range []string{"Thai (U+0E01–U+0E3F)", "Buginese (U+1A00–U+1A0F)"} {
+		if !strings.Contains(hint, want) {
+			t.Errorf("hint %q does not mention %q", hint, want)
+		}
+	}
+
+	// A zero-padded trailer needs no padding runes, but a terminator does
+	hint = StdEncoding.WithZeroPadTrailer().RenderabilityHint()
+	if strings.Contains(hint, "Buginese") {
+		t.Errorf("zero pad trailer: hint %q mentions Buginese", hint)
+	}
+	hint = StdEncoding.WithZeroPadTrailer().WithExplicitTerminator().RenderabilityHint()
+	if !strings.Contains(hint, "terminator needs Buginese (U+1A1E–U+1A1F)") {
+		t.Errorf("terminator: hint %q", hint)
+	}
+
+	if hint := CompactEncoding.RenderabilityHint(); !strings.Contains(hint, "Cyrillic") || strings.Contains(hint, "Thai") {
+		t.Errorf("compact: hint %q", hint)
+	}
+}
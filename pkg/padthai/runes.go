@@ -0,0 +1,98 @@
+package padthai
+
+// runeCounter is a runeWriter that counts runes instead of storing them.
+type runeCounter map[rune]int
+
+func (c runeCounter) WriteRune(r rune) (int, error) {
+	c[r]++
+	return 1, nil
+}
+
+func (c runeCounter) WriteString(s string) (int, error) {
+	for _, r := range s {
+		c[r]++
+	}
+	return len(s), nil
+}
+
+// runeBuffer is a runeWriter collecting runes in a slice.
+type runeBuffer []rune
+
+func (b *runeBuffer) WriteRune(r rune) (int, error) {
+	*b = append(*b, r)
+	return 1, nil
+}
+
+func (b *runeBuffer) WriteString(s string) (int, error) {
+	for _, r := range s {
+		*b = append(*b, r)
+	}
+	return len(s), nil
+}
+
+// UsedRunes returns each rune Encode would emit for data, mapped to the
+// number of times it occurs, for instance to subset a font down to the glyphs
+// a document needs. The encoding itself is never materialized.
+func UsedRunes(data []byte) map[rune]int {
+	return StdEncoding.UsedRunes(data)
+}
+
+// UsedRunes returns each rune enc.Encode would emit for data, mapped to the
+// number of times it occurs.
+func (enc *Encoding) UsedRunes(data []byte) map[rune]int {
+	c := make(runeCounter)
+	encodeTo(enc, c, data)
+	c.WriteString(enc.term)
+	return c
+}
+
+// EncodeRunes is like Encode but returns the encoding as runes, for callers
+// that work on []rune and would otherwise decode the UTF-8 again.
+func EncodeRunes(data []byte) []rune {
+	return StdEncoding.EncodeRunes(data)
+}
+
+// EncodeRunes is like Encode but returns the encoding as runes.
+func (enc *Encoding) EncodeRunes(data []byte) []rune {
+	n := enc.EncodedRuneLen(len(data))
+	if n < 0 {
+		n = 0 // let append grow the slice
+	}
+	return enc.AppendRunes(make([]rune, 0, n), data)
+}
+
+// AppendRunes appends the encoding of data to dst as runes and returns the
+// extended slice, so that a preallocated slice can be reused across calls.
+func (enc *Encoding) AppendRunes(dst []rune, data []byte) []rune {
+	b := runeBuffer(dst)
+	if len(data) > 0 {
+		encodeTo(enc, &b, data)
+	}
+	b.WriteString(enc.term)
+	return b
+}
+
+// DecodeRunes is like Decode but takes the encoding as runes, as returned by
+// EncodeRunes. Whitespace is skipped as in Decode.
+func DecodeRunes(runes []rune) ([]byte, error) {
+	return StdEncoding.DecodeRunes(runes)
+}
+
+// DecodeRunes is like Decode but takes the encoding as runes. The slice is
+// not modified.
+func (enc *Encoding) DecodeRunes(runes []rune) ([]byte, error) {
+	kept := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		keep, err := enc.accept(r, len(kept))
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			if enc.fold != nil {
+				r = enc.fold(r)
+			}
+			kept = append(kept, r)
+		}
+	}
+	return enc.decodeKept(kept)
+}
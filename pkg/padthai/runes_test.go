@@ -0,0 +1,103 @@
+package padthai
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"maps"
+	"testing"
+)
+
+func TestUsedRunes(t *testing.T) {
+	// "Hi" is digits [8 2 9] and "!" is nibbles [2 1]; see TestDump
+	got := UsedRunes([]byte("HiHi!"))
+	want := map[rune]int{
+		ThaiAlphabet[8]:     2,
+		ThaiAlphabet[2]:     2,
+		ThaiAlphabet[9]:     2,
+		BugineseAlphabet[2]: 1,
+		BugineseAlphabet[1]: 1,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("UsedRunes = %v, want %v", got, want)
+	}
+
+	if got := UsedRunes(nil); len(got) != 0 {
+		t.Errorf("UsedRunes(nil) = %v, want empty", got)
+	}
+}
+
+func TestUsedRunesMatchesEncode(t *testing.T) {
+	enc := StdEncoding.WithGroupSize(4).WithExplicitTerminator()
+	input := []byte("The quick brown fox jumps over the lazy dog.")
+
+	want := make(map[rune]int)
+	for _, r := range enc.Encode(input) {
+		want[r]++
+	}
+	if got := enc.UsedRunes(input); !maps.Equal(got, want) {
+		t.Errorf("UsedRunes = %v, want %v", got, want)
+	}
+}
+
+func TestRuneRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 3, 100, 101} {
+		input := make([]byte, size)
+		_, _ = io.ReadFull(rand.Reader, input)
+
+		runes := EncodeRunes(input)
+		if got, want := string(runes), Encode(input); got != want {
+			t.Errorf("size %d: EncodeRunes = %q, want %q", size, got, want)
+		}
+		if cap(runes) != len(runes) {
+			t.Errorf("size %d: capacity %d for %d runes", size, cap(runes), len(runes))
+		}
+		decoded, err := DecodeRunes(append([]rune(" \n"), runes...))
+		if err != nil {
+			t.Fatalf("size %d: DecodeRunes: %v", size, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Errorf("size %d: roundtrip mismatch", size)
+		}
+	}
+
+	// Appending reuses the caller's buffer
+	enc := StdEncoding.WithExplicitTerminator()
+	buf := make([]rune, 0, 64)
+	out := enc.AppendRunes(buf, []byte("Hi!"))
+	if &out[0] != &buf[:1][0] || string(out) != enc.Encode([]byte("Hi!")) {
+		t.Errorf("AppendRunes = %q", string(out))
+	}
+
+	if _, err := DecodeRunes([]rune{ThaiAlphabet[0]}); err == nil {
+		t.Errorf("DecodeRunes accepted a lone rune")
+	}
+}
+
+func TestDecodeRunesMatchesDecode(t *testing.T) {
+	encoded := Encode([]byte("Hello, World!"))
+	for _, s := range []string{
+		encoded,
+		encoded[:len(encoded)-3],             // truncated padding
+		encoded[:len(encoded)-6],             // no padding
+		encoded[:9] + "ᨁ" + encoded[9:],      // padding in the middle
+		"ฯฯฯ",                                // non-canonical
+		"กกกก",                               // invalid length
+		"กขA",                                // invalid character
+		encoded[:9] + "\ufe0f" + encoded[9:], // variation selector
+	} {
+		for _, enc := range []*Encoding{StdEncoding, StdEncoding.Lenient()} {
+			want, wantErr := enc.Decode(s)
+			got, err := enc.DecodeRunes([]rune(s))
+			if !bytes.Equal(got, want) || (err == nil) != (wantErr == nil) {
+				t.Errorf("%q: DecodeRunes = %x, %v; Decode = %x, %v", s, got, err, want, wantErr)
+			}
+			for _, target := range []error{ErrInvalidLength, ErrTruncatedPadding, ErrInvalidPadding, ErrNonCanonical, ErrVariationSelector} {
+				if errors.Is(err, target) != errors.Is(wantErr, target) {
+					t.Errorf("%q: DecodeRunes error %v; Decode error %v", s, err, wantErr)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package padthai
+
+import "strings"
+
+// SanitizeForDecode returns s with the invisible formatting characters that
+// text pasted from web pages tends to carry removed, so that Decode sees only
+// the encoding. It strips exactly these runes, all of which Decode would
+// otherwise reject as invalid characters:
+//
+//   - U+061C ARABIC LETTER MARK
+//   - U+200B–U+200F: zero width space, non-joiner and joiner, and the
+//     left-to-right and right-to-left marks
+//   - U+202A–U+202E: bidirectional embeddings, overrides and their pop
+//   - U+2060–U+2064: word joiner and invisible operators
+//   - U+2066–U+2069: bidirectional isolates and their pop
+//   - U+FEFF ZERO WIDTH NO-BREAK SPACE, the byte order mark
+//
+// Everything else, including whitespace and invalid UTF-8, is left for
+// Decode to skip or report. If s has none of these runes, it is returned
+// unchanged without allocating.
+func SanitizeForDecode(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isInvisibleFormat(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// isInvisibleFormat reports whether SanitizeForDecode strips r.
+func isInvisibleFormat(r rune) bool {
+	switch {
+	case r == '\u061c', r == '\ufeff':
+		return true
+	case r >= '\u200b' && r <= '\u200f':
+		return true
+	case r >= '\u202a' && r <= '\u202e':
+		return true
+	case r >= '\u2060' && r <= '\u2064':
+		return true
+	case r >= '\u2066' && r <= '\u2069':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package padthai
+
+import (
+	"bytes"
+	"testing"
+	"unicode"
+)
+
+func TestSanitizeForDecode(t *testing.T) {
+	input := []byte("Hello, World!")
+	runes := []rune(Encode(input))
+
+	// Wrap the text in a right-to-left override, as a web page might
+	pasted := "\ufeff\u202e" + string(runes[:4]) + "\u200d" + string(runes[4:9]) + "\u200c\u2067" + string(runes[9:]) + "\u2069\u202c"
+	if _, err := Decode(pasted); err == nil {
+		t.Fatalf("test input decoded without sanitizing")
+	}
+
+	decoded, err := Decode(SanitizeForDecode(pasted))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("roundtrip mismatch: got %q, want %q", decoded, input)
+	}
+
+	clean := Encode(input) + "\n"
+	if got := SanitizeForDecode(clean); got != clean {
+		t.Errorf("clean input changed to %q", got)
+	}
+}
+
+func TestInvisibleFormatRanges(t *testing.T) {
+	// Every stripped rune is a format character, and none is in either alphabet
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if !isInvisibleFormat(r) {
+			continue
+		}
+		if !unicode.Is(unicode.Cf, r) {
+			t.Errorf("%U is not a format character", r)
+		}
+		if _, ok := thaiIndex[r]; ok || isBuginese(r) {
+			t.Errorf("%U is in an alphabet", r)
+		}
+	}
+}
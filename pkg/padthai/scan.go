@@ -0,0 +1,43 @@
+package padthai
+
+import "unicode/utf8"
+
+// ScanTokens is a bufio.SplitFunc that returns each maximal run of Thai
+// digits and Buginese padding in its input as a token, skipping everything
+// else, so padthai can be picked out of surrounding text. Whitespace ends a
+// token, so wrapped output is returned line by line.
+//
+// A run longer than the scanner's buffer is not cut short: ScanTokens asks
+// for more data until the run ends, so the scanner grows its buffer up to the
+// limit set with Scanner.Buffer and only then fails with bufio.ErrTooLong.
+func ScanTokens(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	// Skip leading runes that cannot start a token
+	start := 0
+	for start < len(data) {
+		if !atEOF && !utf8.FullRune(data[start:]) {
+			return start, nil, nil
+		}
+		r, size := utf8.DecodeRune(data[start:])
+		if isThai(r) || isBuginese(r) {
+			break
+		}
+		start += size
+	}
+
+	for i := start; i < len(data); {
+		if !atEOF && !utf8.FullRune(data[i:]) {
+			break // the run may continue past a rune cut by the buffer
+		}
+		r, size := utf8.DecodeRune(data[i:])
+		if !isThai(r) && !isBuginese(r) {
+			return i, data[start:i], nil
+		}
+		i += size
+	}
+
+	if atEOF && start < len(data) {
+		return len(data), data[start:], nil
+	}
+	// Keep the start of the run and ask for more data
+	return start, nil, nil
+}
@@ -0,0 +1,55 @@
+package padthai
+
+import (
+	"bufio"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScanTokens(t *testing.T) {
+	a, b := Encode([]byte("first")), Encode([]byte("second!"))
+	input := "From: <" + a + ">\nBody: " + b + " -- end"
+
+	// OneByteReader makes every rune and run straddle buffer boundaries
+	sc := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(input)))
+	sc.Split(ScanTokens)
+	var got []string
+	for sc.Scan() {
+		got = append(got, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if want := []string{a, b}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestScanTokensLong(t *testing.T) {
+	// Longer than bufio.MaxScanTokenSize, the default buffer limit
+	token := Encode(make([]byte, 100*1024))
+
+	sc := bufio.NewScanner(strings.NewReader("x " + token + " y"))
+	sc.Buffer(nil, 1<<20)
+	sc.Split(ScanTokens)
+	if !sc.Scan() {
+		t.Fatalf("scan: %v", sc.Err())
+	}
+	if sc.Text() != token {
+		t.Errorf("got a %d-byte token, want %d bytes", len(sc.Text()), len(token))
+	}
+	if sc.Scan() {
+		t.Errorf("unexpected second token %q", sc.Text())
+	}
+
+	// Without a larger limit, the scanner reports the overflow
+	sc = bufio.NewScanner(strings.NewReader(token))
+	sc.Split(ScanTokens)
+	for sc.Scan() {
+	}
+	if sc.Err() != bufio.ErrTooLong {
+		t.Errorf("default buffer: got %v, want bufio.ErrTooLong", sc.Err())
+	}
+}
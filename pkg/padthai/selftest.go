@@ -0,0 +1,43 @@
+package padthai
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// SelfTest checks that Encode and Decode are exact inverses for all 65,536
+// byte pairs and all 256 trailing odd bytes, and returns an error describing
+// the first mismatch found. It guards against platform or build problems in
+// the byte-order and digit arithmetic, and can be called at program start in
+// paranoid deployments. It takes a few milliseconds.
+func SelfTest() error {
+	corpus := make([]byte, 2*65536)
+	for v := 0; v < 65536; v++ {
+		binary.BigEndian.PutUint16(corpus[2*v:], uint16(v))
+	}
+
+	decoded, err := Decode(Encode(corpus))
+	if err != nil {
+		return fmt.Errorf("padthai: self-test: %w", err)
+	}
+	if len(decoded) != len(corpus) {
+		return fmt.Errorf("padthai: self-test: decoded %d bytes, want %d", len(decoded), len(corpus))
+	}
+	for i := 0; i < len(corpus); i += 2 {
+		want := binary.BigEndian.Uint16(corpus[i:])
+		if got := binary.BigEndian.Uint16(decoded[i:]); got != want {
+			return fmt.Errorf("padthai: self-test: pair %#04x decoded as %#04x", want, got)
+		}
+	}
+
+	for b := 0; b < 256; b++ {
+		decoded, err := Decode(Encode([]byte{byte(b)}))
+		if err != nil {
+			return fmt.Errorf("padthai: self-test: odd byte %#02x: %w", b, err)
+		}
+		if len(decoded) != 1 || decoded[0] != byte(b) {
+			return fmt.Errorf("padthai: self-test: odd byte %#02x decoded as %x", b, decoded)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package padthai
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"unicode/utf8"
+)
+
+// EncodeSeq returns an iterator over the runes of Encode(data), produced
+// lazily as the loop runs, for callers that stream runes to a sink, such as
+// an incremental renderer, rather than build a string. For odd-length data
+// the Buginese padding runes come last.
+func EncodeSeq(data []byte) iter.Seq[rune] {
+	return StdEncoding.EncodeSeq(data)
+}
+
+// EncodeSeq is like the package-level EncodeSeq but uses enc.
+func (enc *Encoding) EncodeSeq(data []byte) iter.Seq[rune] {
+	return func(yield func(rune) bool) {
+		w := yieldWriter{yield: yield}
+		// Encode one group at a time, so that breaking out of the loop stops
+		// the work; the last chunk also holds any trailing bytes
+		for i := 0; i < len(data) && !w.stopped; i += enc.group {
+			encodeTo(enc, &w, data[i:min(i+enc.group, len(data))])
+		}
+		w.WriteString(enc.term)
+	}
+}
+
+// yieldWriter is a runeWriter passing runes to an iterator's yield function,
+// until it asks to stop.
+type yieldWriter struct {
+	yield   func(rune) bool
+	stopped bool
+}
+
+func (w *yieldWriter) WriteRune(r rune) (int, error) {
+	if !w.stopped && !w.yield(r) {
+		w.stopped = true
+	}
+	return utf8.RuneLen(r), nil
+}
+
+func (w *yieldWriter) WriteString(s string) (int, error) {
+	for _, r := range s {
+		w.WriteRune(r)
+	}
+	return len(s), nil
+}
+
+// DecodeSeq returns an iterator over the bytes s decodes to, decoding lazily
+// as the loop runs, so that breaking out of it early leaves the rest of s
+// unread:
+//
+//	for b, err := range padthai.DecodeSeq(s) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// It decodes like the streaming Decoder: bytes are yielded as soon as their
+// digits are known not to be padding, so a malformed input may yield some
+// bytes before its error. An error is yielded once, with a zero byte, and
+// ends the iteration.
+func DecodeSeq(s string) iter.Seq2[byte, error] {
+	return StdEncoding.DecodeSeq(s)
+}
+
+// DecodeSeq is like the package-level DecodeSeq but uses enc.
+func (enc *Encoding) DecodeSeq(s string) iter.Seq2[byte, error] {
+	return func(yield func(byte, error) bool) {
+		rd := runeDecoder{enc: enc}
+		var buf []byte
+		for i, r := range s {
+			if r == utf8.RuneError {
+				if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+					yield(0, &DecodeError{Kind: InvalidUTF8, RuneOffset: rd.pos + rd.n, ByteOffset: i, Rune: utf8.RuneError,
+						Err: fmt.Errorf("%w: byte %#02x at offset %d", ErrInvalidUTF8, s[i], i)})
+					return
+				}
+			}
+			out, err := rd.feed(buf[:0], r)
+			if err != nil {
+				yield(0, err)
+				return
+			}
+			for _, b := range out {
+				if !yield(b, nil) {
+					return
+				}
+			}
+			buf = out
+		}
+
+		out, err := rd.finish(buf[:0])
+		if err != nil {
+			if rd.truncated() {
+				err = fmt.Errorf("%w: %w", io.ErrUnexpectedEOF, err)
+			}
+			yield(0, err)
+			return
+		}
+		for _, b := range out {
+			if !yield(b, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package padthai
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestEncodeSeq(t *testing.T) {
+	for _, enc := range []*Encoding{
+		StdEncoding,
+		StdEncoding.WithGroupSize(4).WithZeroPadTrailer(),
+		StdEncoding.WithMixedTail().WithExplicitTerminator(),
+	} {
+		for _, size := range []int{0, 1, 2, 3, 7, 101} {
+			input := make([]byte, size)
+			_, _ = io.ReadFull(rand.Reader, input)
+
+			got := slices.Collect(enc.EncodeSeq(input))
+			if want := []rune(enc.Encode(input)); !slices.Equal(got, want) {
+				t.Errorf("size %d: got %q, want %q", size, string(got), string(want))
+			}
+		}
+	}
+
+	// Padding comes last, and breaking out stops the iteration
+	runes := slices.Collect(EncodeSeq([]byte("Hi!")))
+	if !isBuginese(runes[len(runes)-1]) || !isBuginese(runes[len(runes)-2]) {
+		t.Errorf("padding is not last in %q", string(runes))
+	}
+	n := 0
+	for range EncodeSeq(make([]byte, 100)) {
+		if n++; n == 4 {
+			break
+		}
+	}
+	if n != 4 {
+		t.Errorf("iterated %d runes after break at 4", n)
+	}
+}
+
+func TestDecodeSeq(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 101} {
+		input := make([]byte, size)
+		_, _ = io.ReadFull(rand.Reader, input)
+
+		var got []byte
+		for b, err := range DecodeSeq(wrapLines(Encode(input), 5)) {
+			if err != nil {
+				t.Fatalf("size %d: %v", size, err)
+			}
+			got = append(got, b)
+		}
+		if !bytes.Equal(got, input) {
+			t.Errorf("size %d: roundtrip mismatch", size)
+		}
+	}
+}
+
+func TestDecodeSeqBreak(t *testing.T) {
+	// The input is invalid far past the bytes read, so only stopping early
+	// avoids the error
+	encoded := Encode([]byte("Hello, World! Hello, World! Hello, World!"))[:90] + "ABC"
+
+	var got []byte
+	for b, err := range DecodeSeq(encoded) {
+		if err != nil {
+			t.Fatalf("error after %d bytes: %v", len(got), err)
+		}
+		if got = append(got, b); len(got) == 2 {
+			break
+		}
+	}
+	if string(got) != "He" {
+		t.Errorf("got %q, want %q", got, "He")
+	}
+}
+
+func TestDecodeSeqError(t *testing.T) {
+	encoded := Encode([]byte("Hello, World! Hello, World! Hello, World!"))[:90] + "ABC"
+
+	errs, n := 0, 0
+	for b, err := range DecodeSeq(encoded) {
+		if err != nil {
+			errs++
+			if b != 0 {
+				t.Errorf("error yielded with byte %#02x", b)
+			}
+			continue // the iterator must end by itself
+		}
+		n++
+	}
+	if errs != 1 {
+		t.Errorf("%d errors yielded, want 1", errs)
+	}
+	if n > 20 {
+		t.Errorf("%d bytes yielded before the error from 30 valid runes", n)
+	}
+}
@@ -0,0 +1,113 @@
+package padthai
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// SplitAtByteLimit splits the padthai string s into a head of at most
+// maxBytes bytes and the tail that follows it, for storage in fixed-size
+// fields. The split falls between groups of three digits, counting only
+// runes that Decode keeps, so that each half is valid UTF-8 and decodes on
+// its own, and decoding head then tail yields the bytes of s. Whitespace just
+// before the split stays in head.
+//
+// If s fits in maxBytes, head is s and tail is empty. If not even one triplet
+// fits, head is empty.
+func SplitAtByteLimit(s string, maxBytes int) (head, tail string) {
+	if len(s) <= maxBytes {
+		return s, ""
+	}
+	split, kept := 0, 0
+	for i, r := range s {
+		if i > maxBytes {
+			break
+		}
+		if kept%3 == 0 {
+			split = i
+		}
+		if !isSpace(r) {
+			kept++
+		}
+	}
+	return s[:split], s[split:]
+}
+
+// DecodeUpTo decodes a prefix of s using StdEncoding, decoding at most
+// maxRunes runes' worth of whole groups, and returns the bytes decoded along
+// with the number of bytes of s consumed. It bounds the work of each call, so
+// that a server decoding for many clients can decode a long input in turns:
+// call it again with s[consumed:] until all of s is consumed. Decoding the
+// pieces yields the bytes Decode(s) would.
+//
+// The rest of s is consumed whole once it fits in maxRunes. Otherwise the
+// prefix ends between groups, and partial groups are left for the next call,
+// as are the final group and its padding, which are decoded together. A call
+// whose budget cannot fit a group consumes nothing; a maxRunes of 6 always
+// makes progress. Positions in errors are relative to s.
+func DecodeUpTo(s string, maxRunes int) (data []byte, consumed int, err error) {
+	return StdEncoding.DecodeUpTo(s, maxRunes)
+}
+
+// DecodeUpTo is like the package-level DecodeUpTo but uses enc. Groups are
+// triplets, or 6-digit words under WithGroupSize(4); with the built-in tails,
+// twice that many runes always make progress.
+func (enc *Encoding) DecodeUpTo(s string, maxRunes int) (data []byte, consumed int, err error) {
+	unit := 3 * enc.group / 2
+	// s cannot hold more runes than bytes, which also bounds the lookahead
+	// below, so that no call scans further into s than it needs to
+	maxRunes = min(max(maxRunes, 0), len(s))
+	// Look past the budget far enough to tell whether the group at its end
+	// is the last one, which is left to Decode along with the tail
+	limit := maxRunes + unit + 1
+	runes := make([]rune, 0, limit)
+	// starts[k] is the byte offset in s of group k
+	var starts []int
+	whole := true
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return nil, 0, &DecodeError{Kind: InvalidUTF8, RuneOffset: len(runes), ByteOffset: i, Rune: utf8.RuneError,
+					Err: fmt.Errorf("%w: byte %#02x at offset %d", ErrInvalidUTF8, s[i], i)}
+			}
+		}
+		keep, err := enc.accept(r, len(runes))
+		if err != nil {
+			return nil, 0, err
+		}
+		if !keep {
+			continue
+		}
+		if len(runes) == limit {
+			whole = false
+			break
+		}
+		if len(runes)%unit == 0 {
+			starts = append(starts, i)
+		}
+		if enc.fold != nil {
+			r = enc.fold(r)
+		}
+		runes = append(runes, r)
+	}
+
+	if whole && len(runes) <= maxRunes {
+		data, err := enc.Decode(s)
+		if err != nil {
+			return nil, 0, err
+		}
+		return data, len(s), nil
+	}
+
+	// Take the most whole groups that fit, leaving at least a group and
+	// whatever follows it, so that any tail is decoded by a later call
+	n := min(maxRunes, len(runes)-unit-1) / unit
+	if n <= 0 {
+		return []byte{}, 0, nil
+	}
+	data, err = enc.decodeGroups(make([]byte, 0, n*enc.group), runes[:n*unit], 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, starts[n], nil
+}
@@ -0,0 +1,95 @@
+package padthai
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitAtByteLimit(t *testing.T) {
+	input := []byte("Hello, World!")
+	encoded := wrapLines(Encode(input), 4)
+
+	for limit := 0; limit <= len(encoded)+1; limit++ {
+		head, tail := SplitAtByteLimit(encoded, limit)
+		if head+tail != encoded {
+			t.Fatalf("limit %d: halves do not rejoin", limit)
+		}
+		if len(head) > limit || !utf8.ValidString(head) || !utf8.ValidString(tail) {
+			t.Fatalf("limit %d: bad split %q | %q", limit, head, tail)
+		}
+		// Thai runes take 3 bytes, so the head holds every whole triplet
+		// that fits, with at most one line break per 4 runes
+		if tail != "" && limit-len(head) >= 9+3 {
+			t.Errorf("limit %d: head of %d bytes is too short", limit, len(head))
+		}
+
+		first, err := Decode(head)
+		if err != nil {
+			t.Fatalf("limit %d: decode head: %v", limit, err)
+		}
+		second, err := Decode(tail)
+		if err != nil {
+			t.Fatalf("limit %d: decode tail: %v", limit, err)
+		}
+		if got := append(first, second...); !bytes.Equal(got, input) {
+			t.Errorf("limit %d: halves decode to %q", limit, got)
+		}
+	}
+
+	// One byte short of a triplet boundary backs off to the previous one
+	encoded = Encode(input)
+	if head, _ := SplitAtByteLimit(encoded, 17); utf8.RuneCountInString(head) != 3 {
+		t.Errorf("limit 17: head has %d runes, want 3", utf8.RuneCountInString(head))
+	}
+}
+
+func TestDecodeUpTo(t *testing.T) {
+	input := make([]byte, 301)
+	for i := range input {
+		input[i] = byte(i * 7)
+	}
+
+	for _, enc := range []*Encoding{
+		StdEncoding,
+		StdEncoding.WithExplicitTerminator(),
+		StdEncoding.WithZeroPadTrailer(),
+		StdEncoding.WithGroupSize(4),
+	} {
+		encoded := wrapLines(enc.Encode(input), 10)
+		for _, budget := range []int{12, 13, 50, 1000} {
+			var got []byte
+			s, calls := encoded, 0
+			for s != "" {
+				data, consumed, err := enc.DecodeUpTo(s, budget)
+				if err != nil {
+					t.Fatalf("budget %d: %v", budget, err)
+				}
+				if consumed == 0 {
+					t.Fatalf("budget %d: no progress with %d bytes left", budget, len(s))
+				}
+				if 2*len(data) > 3*budget {
+					t.Fatalf("budget %d: decoded %d bytes in one call", budget, len(data))
+				}
+				got = append(got, data...)
+				s = s[consumed:]
+				calls++
+			}
+			if !bytes.Equal(got, input) {
+				t.Errorf("budget %d: decoded %x", budget, got)
+			}
+			if budget < 300 && calls < 300*3/2/budget {
+				t.Errorf("budget %d: only %d calls", budget, calls)
+			}
+		}
+	}
+
+	// A budget too small for a group consumes nothing
+	if data, consumed, err := DecodeUpTo(Encode(input), 2); err != nil || consumed != 0 || len(data) != 0 {
+		t.Errorf("budget 2: got %d bytes, consumed %d, err %v", len(data), consumed, err)
+	}
+	if _, _, err := DecodeUpTo(Encode(input)[:9]+"\xff"+Encode(input)[9:], 3); !errors.Is(err, ErrInvalidUTF8) {
+		t.Errorf("invalid UTF-8: got %v", err)
+	}
+}
@@ -0,0 +1,586 @@
+package padthai
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encoder is a streaming padthai encoder.
+//
+// Bytes written to an Encoder are encoded and written to the underlying
+// writer as soon as they form complete 2-byte pairs, unless a flush threshold
+// is set. An odd trailing byte is held back until Close, which writes it as
+// Buginese padding.
+type Encoder struct {
+	w     io.Writer
+	carry byte // pending first byte of an incomplete pair
+	odd   bool // carry is valid
+	err   error
+
+	threshold int    // runes to buffer before writing; 0 writes at once
+	buf       []byte // encoded output not yet written
+	nbuf      int    // runes in buf
+
+	stats EncoderStats
+}
+
+// EncoderStats counts the data an Encoder has processed.
+type EncoderStats struct {
+	BytesIn  int64 // input bytes accepted by Write and WriteString
+	RunesOut int64 // runes written to the underlying writer
+	BytesOut int64 // UTF-8 bytes written to the underlying writer
+}
+
+// Stats returns the counts of input consumed and output written so far.
+// Output still buffered, or held back as a pending odd byte, is not counted
+// until it is written, so after a successful Close the counts are complete,
+// including the final padding.
+func (e *Encoder) Stats() EncoderStats {
+	return e.stats
+}
+
+// NewEncoder returns an Encoder that writes the padthai encoding of
+// everything written to it to w. The caller must Close the Encoder to flush
+// any trailing padding.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// WithFlushThreshold makes e hold its output until at least runes runes have
+// accumulated, then write them to the underlying writer at once. Close and
+// Flush write any remainder.
+//
+// An Encoder never holds output in a buffer of its own by default: every
+// Write's output is written at once, which gives the lowest latency but one
+// underlying write per Write. The threshold is for sources that write a few
+// bytes at a time to a writer where every write costs a syscall or a
+// message, such as a relay: it bounds how much output waits, and so how
+// late it can appear, while saving the writes in between. Call Flush to send
+// what is held early, for instance at the end of a message.
+//
+// It returns e, for chaining after NewEncoder.
+func (e *Encoder) WithFlushThreshold(runes int) *Encoder {
+	e.threshold = runes
+	return e
+}
+
+// Write encodes p and writes the result to the underlying writer.
+func (e *Encoder) Write(p []byte) (int, error) {
+	return encoderWrite(e, p)
+}
+
+// WriteString is like Write but takes a string, avoiding a copy into a byte
+// slice. Calls to Write and WriteString may be freely interleaved.
+func (e *Encoder) WriteString(s string) (int, error) {
+	return encoderWrite(e, s)
+}
+
+// encoderWrite implements Encoder.Write and Encoder.WriteString.
+func encoderWrite[T string | []byte](e *Encoder, p T) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n := len(p)
+	if n == 0 {
+		return 0, nil
+	}
+
+	// Complete a pair left over from the previous Write
+	if e.odd {
+		if e.err = e.emit(Encode([]byte{e.carry, p[0]})); e.err != nil {
+			return 0, e.err
+		}
+		e.odd = false
+		p = p[1:]
+		e.stats.BytesIn++
+	}
+
+	even := len(p) &^ 1
+	if even > 0 {
+		if e.err = e.emit(encode(StdEncoding, p[:even])); e.err != nil {
+			return n - len(p), e.err
+		}
+	}
+
+	if even < len(p) {
+		e.carry = p[even]
+		e.odd = true
+	}
+	e.stats.BytesIn += int64(len(p))
+	return n, nil
+}
+
+// emit writes encoded output, or buffers it under the flush threshold.
+func (e *Encoder) emit(s string) error {
+	if e.threshold <= 0 {
+		n, err := io.WriteString(e.w, s)
+		e.stats.RunesOut += int64(utf8.RuneCountInString(s[:n]))
+		e.stats.BytesOut += int64(n)
+		return err
+	}
+	e.buf = append(e.buf, s...)
+	e.nbuf += utf8.RuneCountInString(s)
+	if e.nbuf < e.threshold {
+		return nil
+	}
+	return e.Flush()
+}
+
+// Flush writes any output buffered under the flush threshold to the
+// underlying writer. A pending odd byte stays pending until Close.
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	if len(e.buf) > 0 {
+		var n int
+		n, e.err = e.w.Write(e.buf)
+		e.stats.RunesOut += int64(utf8.RuneCount(e.buf[:n]))
+		e.stats.BytesOut += int64(n)
+		e.buf, e.nbuf = e.buf[:0], 0
+	}
+	return e.err
+}
+
+// Close flushes any pending odd byte as Buginese padding, and any buffered
+// output. It does not close the underlying writer.
+//
+// The output then matches Encode of everything written, however it was split
+// across writes, including empty ones: two padding runes if the total was
+// odd, none if it was even. Calling Close again writes nothing more.
+func (e *Encoder) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.odd {
+		e.odd = false
+		if e.err = e.emit(Encode([]byte{e.carry})); e.err != nil {
+			return e.err
+		}
+	}
+	return e.Flush()
+}
+
+// teeEncoder forwards raw bytes to one writer and their encoding to another.
+type teeEncoder struct {
+	enc *Encoder
+	raw io.Writer
+}
+
+// NewTeeEncoder returns a WriteCloser that writes every byte written to it
+// unchanged to raw, and its padthai encoding to encoded. It is the encoding
+// counterpart of io.TeeReader.
+//
+// Close flushes trailing padding to encoded only; raw never sees padding.
+func NewTeeEncoder(encoded io.Writer, raw io.Writer) io.WriteCloser {
+	return &teeEncoder{enc: NewEncoder(encoded), raw: raw}
+}
+
+func (t *teeEncoder) Write(p []byte) (int, error) {
+	if n, err := t.raw.Write(p); err != nil {
+		return n, err
+	}
+	return t.enc.Write(p)
+}
+
+func (t *teeEncoder) Close() error {
+	return t.enc.Close()
+}
+
+// IncrementalEncoder encodes bytes fed to it one at a time, returning runes
+// as soon as each group of input bytes is complete. It is the counterpart of
+// IncrementalDecoder.
+type IncrementalEncoder struct {
+	enc     *Encoding
+	pending [4]byte
+	n       int // bytes in pending
+}
+
+// NewIncrementalEncoder returns an IncrementalEncoder using StdEncoding.
+func NewIncrementalEncoder() *IncrementalEncoder {
+	return StdEncoding.NewIncrementalEncoder()
+}
+
+// NewIncrementalEncoder returns an IncrementalEncoder using enc.
+func (enc *Encoding) NewIncrementalEncoder() *IncrementalEncoder {
+	return &IncrementalEncoder{enc: enc}
+}
+
+// Feed accepts the next input byte and returns the runes it completes, if
+// any: 3 runes per byte pair, or 6 per word with a group size of 4.
+func (e *IncrementalEncoder) Feed(b byte) []rune {
+	e.pending[e.n] = b
+	e.n++
+	if e.n < e.enc.group {
+		return nil
+	}
+	var out runeBuffer
+	encodeTo(e.enc, &out, e.pending[:e.n])
+	e.n = 0
+	return out
+}
+
+// Finish encodes any pending bytes, such as an odd trailing byte as padding,
+// and returns the final runes including the terminator, if enc has one.
+func (e *IncrementalEncoder) Finish() []rune {
+	var out runeBuffer
+	encodeTo(e.enc, &out, e.pending[:e.n])
+	out.WriteString(e.enc.term)
+	e.n = 0
+	return out
+}
+
+// decodeWindow is the number of runes a runeDecoder holds back. It must be
+// longer than a group plus the longest possible tail, so that the runes it
+// releases to be decoded as a group can never be padding.
+const decodeWindow = 16
+
+// runeDecoder is the rune-at-a-time state machine behind the streaming
+// decoders. It holds back the most recent runes, which may turn out to be
+// padding, and decodes older runes group by group.
+type runeDecoder struct {
+	enc *Encoding
+	buf [decodeWindow]rune
+	n   int // runes in buf
+	pos int // input position of buf[0]
+}
+
+// feed accepts the next input rune and appends any bytes it releases to dst.
+func (d *runeDecoder) feed(dst []byte, r rune) ([]byte, error) {
+	keep, err := d.enc.accept(r, d.pos+d.n)
+	if err != nil || !keep {
+		return dst, err
+	}
+	if d.enc.fold != nil {
+		r = d.enc.fold(r)
+	}
+	// Reject foreign runes now rather than when their group is decoded, so
+	// that the error is reported while the rune is current
+	if _, ok := d.enc.digit(r); !ok && !d.enc.isPad(r) && !strings.ContainsRune(d.enc.term, r) {
+		return dst, corrupt(InvalidChar, d.pos+d.n, r, fmt.Errorf("%w %U at position %d", ErrInvalidCharacter, r, d.pos+d.n))
+	}
+	if d.n == len(d.buf) {
+		g := d.enc.group / 2 * 3
+		out, err := d.enc.decodeGroups(dst, d.buf[:g], d.pos)
+		if err != nil {
+			return dst, err
+		}
+		dst = out
+		d.n = copy(d.buf[:], d.buf[g:d.n])
+		d.pos += g
+	}
+	d.buf[d.n] = r
+	d.n++
+	return dst, nil
+}
+
+// finish decodes the held-back runes at the end of the input and appends the
+// result to dst.
+func (d *runeDecoder) finish(dst []byte) ([]byte, error) {
+	if d.n == 0 && d.enc.term == "" {
+		return dst, nil
+	}
+	out, err := d.enc.decodeRunes(dst, d.buf[:d.n], d.pos)
+	if err != nil {
+		return dst, err
+	}
+	d.pos += d.n
+	d.n = 0
+	return out, nil
+}
+
+// IncrementalDecoder decodes padthai text fed to it one rune at a time, for
+// callers that receive input through callbacks rather than an io.Reader.
+//
+// Runes near the end of the input may turn out to be padding or a terminator,
+// so an IncrementalDecoder holds back the last few runes it has seen: decoded
+// bytes are released once a group is followed by enough further input, and
+// the rest by Finish.
+type IncrementalDecoder struct {
+	rd  runeDecoder
+	err error // sticky error, returned by every later call
+}
+
+// NewIncrementalDecoder returns an IncrementalDecoder using StdEncoding.
+func NewIncrementalDecoder() *IncrementalDecoder {
+	return StdEncoding.NewIncrementalDecoder()
+}
+
+// NewIncrementalDecoder returns an IncrementalDecoder using enc.
+func (enc *Encoding) NewIncrementalDecoder() *IncrementalDecoder {
+	return &IncrementalDecoder{rd: runeDecoder{enc: enc}}
+}
+
+// Feed accepts the next input rune and returns any bytes it completes.
+// Whitespace is skipped, as in Decode. Once Feed or Finish has returned an
+// error, every later call returns it again.
+func (d *IncrementalDecoder) Feed(r rune) ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	out, err := d.rd.feed(nil, r)
+	d.err = err
+	return out, err
+}
+
+// Finish signals the end of the input and returns the remaining bytes,
+// including any trailing padding byte.
+func (d *IncrementalDecoder) Finish() ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	out, err := d.rd.finish(nil)
+	d.err = err
+	return out, err
+}
+
+// truncated reports whether the held-back runes end part-way through a group
+// of digits, as when the input is cut short.
+func (d *runeDecoder) truncated() bool {
+	if d.n == 0 || d.enc.term != "" || d.enc.isPad(d.buf[d.n-1]) {
+		return false
+	}
+	switch d.enc.tail {
+	case tailZero:
+		return d.n%3 == 2 // n%3 == 1 ends with the trailer marker
+	case tailThai:
+		return d.n%3 == 1 // n%3 == 2 ends with the tail
+	}
+	return d.n%3 != 0
+}
+
+// Decoder is a streaming padthai decoder. It reads padthai text from an
+// underlying reader and returns the decoded bytes, using a fixed amount of
+// memory regardless of the input size or layout: skipped runes such as
+// whitespace are discarded as they are read, and only the last few digits,
+// which may turn out to be padding, are held back. Adversarial input, such
+// as megabytes of whitespace between digits, cannot make it buffer more.
+type Decoder struct {
+	r   io.Reader
+	rd  runeDecoder
+	in  [1024]byte
+	nin int    // bytes of an incomplete rune carried at the start of in
+	buf []byte // storage for out, reused across fills
+	out []byte // decoded bytes not yet returned by Read
+	err error  // sticky error, returned once out is drained
+
+	runeOff, byteOff int // input consumed by rd
+}
+
+// NewDecoder returns a Decoder that decodes padthai text read from r using
+// StdEncoding.
+func NewDecoder(r io.Reader) *Decoder {
+	return StdEncoding.NewDecoder(r)
+}
+
+// NewDecoder returns a Decoder that decodes text read from r using enc.
+func (enc *Encoding) NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r, rd: runeDecoder{enc: enc}}
+}
+
+// Read reads decoded bytes into p. Whitespace in the input is skipped, as in
+// Decode. It returns io.EOF once the input is exhausted and all of it has
+// been decoded. If the input ends part-way through a group of digits, the
+// error wraps io.ErrUnexpectedEOF, telling truncation apart from corruption.
+func (d *Decoder) Read(p []byte) (int, error) {
+	if err := d.more(); err != nil {
+		return 0, err
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// ReadByte returns the next decoded byte, or io.EOF at the end of the input.
+// Together with Read, it makes a Decoder an io.ByteReader.
+func (d *Decoder) ReadByte() (byte, error) {
+	if err := d.more(); err != nil {
+		return 0, err
+	}
+	b := d.out[0]
+	d.out = d.out[1:]
+	return b, nil
+}
+
+// Position returns how far d has consumed its input, as counts of runes and
+// bytes including skipped whitespace. The decoder reads ahead of what Read
+// has returned, in chunks of up to 1 KiB.
+//
+// Once Read has returned an error about a rune itself, such as an invalid
+// character or variation selector, Position is the offset of that rune.
+// Errors about a group of runes, or about the end of the input, are only
+// detected further on, and Position then points past the culprit.
+func (d *Decoder) Position() (runeOffset, byteOffset int) {
+	return d.runeOff, d.byteOff
+}
+
+// more ensures d.out holds at least one decoded byte, or returns the error
+// that prevents it.
+func (d *Decoder) more() error {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return d.err
+		}
+		d.out = d.fill(d.buf[:0])
+		d.buf = d.out
+	}
+	return nil
+}
+
+// fill reads the next chunk of input and appends its decoding to out,
+// recording the end of the input or any failure in d.err.
+func (d *Decoder) fill(out []byte) []byte {
+	n, rerr := d.r.Read(d.in[d.nin:])
+	n += d.nin
+
+	var err error
+	i := 0
+	for i < n {
+		// Leave an incomplete rune for the next Read, unless there is none;
+		// after a read error it is left unread, so that the error is the
+		// read error rather than a bogus invalid rune
+		if rerr != io.EOF && !utf8.FullRune(d.in[i:n]) {
+			break
+		}
+		r, size := utf8.DecodeRune(d.in[i:n])
+		if out, err = d.rd.feed(out, r); err != nil {
+			break
+		}
+		i += size
+		d.runeOff++
+		d.byteOff += size
+	}
+	d.nin = copy(d.in[:], d.in[i:n])
+
+	switch {
+	case err != nil:
+		d.err = err
+	case rerr == io.EOF:
+		if out, err = d.rd.finish(out); err != nil {
+			if d.rd.truncated() {
+				err = fmt.Errorf("%w: %w", io.ErrUnexpectedEOF, err)
+			}
+			d.err = err
+		} else {
+			d.err = io.EOF
+		}
+	case rerr != nil:
+		d.err = rerr
+	}
+	return out
+}
+
+// DecodeReaderAll reads r until EOF and returns the decoded bytes. Unlike
+// decoding the result of io.ReadAll, it never holds the encoded text in
+// memory: input is decoded chunk by chunk straight into the output slice.
+func DecodeReaderAll(r io.Reader) ([]byte, error) {
+	return StdEncoding.DecodeReaderAll(r)
+}
+
+// DecodeReaderAll is like the package-level DecodeReaderAll but uses enc.
+func (enc *Encoding) DecodeReaderAll(r io.Reader) ([]byte, error) {
+	d := enc.NewDecoder(r)
+	out := []byte{}
+	for d.err == nil {
+		out = d.fill(out)
+	}
+	if d.err != io.EOF {
+		return nil, d.err
+	}
+	return out, nil
+}
+
+// DecodeRuneReader reads runes from rr until EOF and returns the decoded
+// bytes. It is DecodeReaderAll for sources that have already decoded their
+// UTF-8, such as a bufio.Reader, and consumes their runes directly.
+// Whitespace is skipped as in Decode, and if the input ends part-way through
+// a group of digits, the error wraps io.ErrUnexpectedEOF.
+func DecodeRuneReader(rr io.RuneReader) ([]byte, error) {
+	return StdEncoding.DecodeRuneReader(rr)
+}
+
+// DecodeRuneReader is like the package-level DecodeRuneReader but uses enc.
+func (enc *Encoding) DecodeRuneReader(rr io.RuneReader) ([]byte, error) {
+	rd := runeDecoder{enc: enc}
+	out := []byte{}
+	for off := 0; ; {
+		r, size, err := rr.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r == utf8.RuneError && size == 1 {
+			return nil, &DecodeError{Kind: InvalidUTF8, RuneOffset: rd.pos + rd.n, ByteOffset: off, Rune: utf8.RuneError,
+				Err: fmt.Errorf("%w: at offset %d", ErrInvalidUTF8, off)}
+		}
+		if out, err = rd.feed(out, r); err != nil {
+			return nil, err
+		}
+		off += size
+	}
+
+	out, err := rd.finish(out)
+	if err != nil {
+		if rd.truncated() {
+			err = fmt.Errorf("%w: %w", io.ErrUnexpectedEOF, err)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// countWriter counts the bytes successfully written through it.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// EncodeReaderTo encodes everything read from src and writes the encoding to
+// dst, using a bounded amount of memory. It returns the number of bytes
+// written to dst and the first read or write error encountered.
+func EncodeReaderTo(dst io.Writer, src io.Reader) (written int64, err error) {
+	cw := &countWriter{w: dst}
+	enc := NewEncoder(cw)
+	if _, err := io.Copy(enc, src); err != nil {
+		return cw.n, err
+	}
+	err = enc.Close()
+	return cw.n, err
+}
+
+// DecodeReaderTo decodes the padthai text read from src and writes the
+// decoded bytes to dst, using a bounded amount of memory. It returns the
+// number of bytes written to dst and the first read, decode or write error
+// encountered.
+func DecodeReaderTo(dst io.Writer, src io.Reader) (written int64, err error) {
+	return io.Copy(dst, NewDecoder(src))
+}
+
+// DecodeToWriter decodes the padthai string s and writes the decoded bytes to
+// dst as decoding progresses, without building the whole result in memory. It
+// returns the number of bytes written and the first decode or write error
+// encountered; on error, dst may already hold the bytes decoded before it.
+func DecodeToWriter(dst io.Writer, s string) (int64, error) {
+	return DecodeReaderTo(dst, strings.NewReader(s))
+}
+
+// NewBytesReader decodes s and returns a reader over the decoded bytes, for
+// APIs that take an io.Reader. It returns the same errors as Decode.
+func NewBytesReader(s string) (*bytes.Reader, error) {
+	data, err := Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
@@ -0,0 +1,661 @@
+package padthai
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestEncoderMatchesEncode(t *testing.T) {
+	input := make([]byte, 1001)
+	_, _ = io.ReadFull(rand.Reader, input)
+
+	// Feed the input in uneven chunks to exercise the odd-byte carry
+	for _, chunk := range []int{1, 2, 3, 7, 64, 1001} {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		for i := 0; i < len(input); i += chunk {
+			end := min(i+chunk, len(input))
+			if _, err := enc.Write(input[i:end]); err != nil {
+				t.Fatalf("chunk %d: write: %v", chunk, err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("chunk %d: close: %v", chunk, err)
+		}
+		if got, want := buf.String(), Encode(input); got != want {
+			t.Errorf("chunk %d: streaming output differs from Encode", chunk)
+		}
+	}
+}
+
+func TestTeeEncoder(t *testing.T) {
+	input := []byte("Hello, World!") // odd length: needs padding
+
+	var encoded, raw bytes.Buffer
+	tee := NewTeeEncoder(&encoded, &raw)
+	if _, err := tee.Write(input[:5]); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := tee.Write(input[5:]); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// Before Close, the odd trailing byte is still pending
+	if got, want := encoded.String(), Encode(input[:len(input)-1]); got != want {
+		t.Errorf("before close: encoded = %q, want %q", got, want)
+	}
+
+	rawBefore := raw.Len()
+	if err := tee.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if raw.Len() != rawBefore {
+		t.Errorf("close wrote %d bytes to the raw sink", raw.Len()-rawBefore)
+	}
+
+	if !bytes.Equal(raw.Bytes(), input) {
+		t.Errorf("raw = %q, want %q", raw.Bytes(), input)
+	}
+	if got, want := encoded.String(), Encode(input); got != want {
+		t.Errorf("encoded = %q, want %q", got, want)
+	}
+}
+
+// errWriter accepts limit bytes, then fails.
+type errWriter struct {
+	limit int
+}
+
+var errSink = errors.New("sink failed")
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errSink
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+var errSource = errors.New("source failed")
+
+func TestDecoderMatchesDecode(t *testing.T) {
+	input := make([]byte, 3001)
+	_, _ = io.ReadFull(rand.Reader, input)
+	encoded := wrapLines(Encode(input), 76)
+
+	// OneByteReader splits every rune across reads
+	for _, r := range []io.Reader{strings.NewReader(encoded), iotest.OneByteReader(strings.NewReader(encoded))} {
+		decoded, err := io.ReadAll(NewDecoder(r))
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Errorf("streaming output differs from input")
+		}
+	}
+}
+
+func TestDecoderInvalidInput(t *testing.T) {
+	for _, encoded := range []string{"ABC", string(BugineseAlphabet[0])} {
+		_, want := Decode(encoded)
+		_, err := io.ReadAll(NewDecoder(strings.NewReader(encoded)))
+		if err == nil || err.Error() != want.Error() {
+			t.Errorf("%q: got error %v, want %v", encoded, err, want)
+		}
+	}
+}
+
+func TestEncodeReaderTo(t *testing.T) {
+	input := []byte("Hello, World!")
+
+	var buf bytes.Buffer
+	n, err := EncodeReaderTo(&buf, bytes.NewReader(input))
+	if err != nil {
+		t.Fatalf("EncodeReaderTo: %v", err)
+	}
+	if want := Encode(input); buf.String() != want || n != int64(len(want)) {
+		t.Errorf("got %q (%d bytes), want %q", buf.String(), n, want)
+	}
+
+	// Read error
+	src := io.MultiReader(bytes.NewReader(input), iotest.ErrReader(errSource))
+	if _, err := EncodeReaderTo(io.Discard, src); !errors.Is(err, errSource) {
+		t.Errorf("read error: got %v, want %v", err, errSource)
+	}
+
+	// Write error part-way through
+	n, err = EncodeReaderTo(&errWriter{limit: 10}, bytes.NewReader(input))
+	if !errors.Is(err, errSink) || n != 10 {
+		t.Errorf("write error: got (%d, %v), want (10, %v)", n, err, errSink)
+	}
+}
+
+func TestDecodeReaderTo(t *testing.T) {
+	input := []byte("Hello, World!")
+	encoded := Encode(input)
+
+	var buf bytes.Buffer
+	n, err := DecodeReaderTo(&buf, strings.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("DecodeReaderTo: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), input) || n != int64(len(input)) {
+		t.Errorf("got %q (%d bytes), want %q", buf.Bytes(), n, input)
+	}
+
+	// Read error
+	src := io.MultiReader(strings.NewReader(encoded[:9]), iotest.ErrReader(errSource))
+	if _, err := DecodeReaderTo(io.Discard, src); !errors.Is(err, errSource) {
+		t.Errorf("read error: got %v, want %v", err, errSource)
+	}
+	// A rune cut off by the error is not reported as invalid input
+	src = io.MultiReader(strings.NewReader(encoded[:10]), iotest.ErrReader(errSource))
+	if _, err := DecodeReaderTo(io.Discard, src); !errors.Is(err, errSource) {
+		t.Errorf("read error mid-rune: got %v, want %v", err, errSource)
+	}
+
+	// Write error part-way through
+	n, err = DecodeReaderTo(&errWriter{limit: 4}, strings.NewReader(encoded))
+	if !errors.Is(err, errSink) || n != 4 {
+		t.Errorf("write error: got (%d, %v), want (4, %v)", n, err, errSink)
+	}
+}
+
+// wrapLines inserts a newline after every width runes of s.
+func wrapLines(s string, width int) string {
+	var sb strings.Builder
+	for i, r := range []rune(s) {
+		if i > 0 && i%width == 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func TestDecodeReaderAll(t *testing.T) {
+	input := make([]byte, 5001)
+	_, _ = io.ReadFull(rand.Reader, input)
+
+	decoded, err := DecodeReaderAll(strings.NewReader(wrapLines(Encode(input), 76)))
+	if err != nil {
+		t.Fatalf("DecodeReaderAll: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("roundtrip mismatch")
+	}
+
+	if _, err := DecodeReaderAll(strings.NewReader("ABC")); err == nil {
+		t.Error("expected error for invalid input, got nil")
+	}
+
+	decoded, err = DecodeReaderAll(strings.NewReader(""))
+	if err != nil || len(decoded) != 0 {
+		t.Errorf("empty input: got (%x, %v), want empty output", decoded, err)
+	}
+}
+
+func benchmarkEncoded(b *testing.B) string {
+	input := make([]byte, 64*1024)
+	_, _ = io.ReadFull(rand.Reader, input)
+	b.SetBytes(int64(len(input)))
+	return Encode(input)
+}
+
+func BenchmarkDecodeReaderAll(b *testing.B) {
+	encoded := benchmarkEncoded(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = DecodeReaderAll(strings.NewReader(encoded))
+	}
+}
+
+// BenchmarkDecodeReadAll is the two-copy baseline DecodeReaderAll replaces.
+func BenchmarkDecodeReadAll(b *testing.B) {
+	encoded := benchmarkEncoded(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		input, _ := io.ReadAll(strings.NewReader(encoded))
+		_, _ = Decode(string(input))
+	}
+}
+
+func TestDecoderReadByte(t *testing.T) {
+	input := make([]byte, 2049)
+	_, _ = io.ReadFull(rand.Reader, input)
+	encoded := Encode(input)
+
+	want, err := Decode(encoded)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	var br io.ByteReader = NewDecoder(strings.NewReader(encoded))
+	var got []byte
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadByte after %d bytes: %v", len(got), err)
+		}
+		got = append(got, b)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("byte-by-byte output differs from Decode")
+	}
+}
+
+func TestEncoderWriteString(t *testing.T) {
+	parts := []string{"Hel", "lo", ", ", "W", "orld", "!"} // 13 bytes in total
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	var _ io.StringWriter = enc
+	for i, part := range parts {
+		var err error
+		if i%2 == 0 {
+			_, err = enc.WriteString(part)
+		} else {
+			_, err = enc.Write([]byte(part))
+		}
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if want := Encode([]byte(strings.Join(parts, ""))); buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestIncrementalDecoder(t *testing.T) {
+	input := make([]byte, 301)
+	_, _ = io.ReadFull(rand.Reader, input)
+	encoded := wrapLines(Encode(input), 10)
+
+	d := NewIncrementalDecoder()
+	var got []byte
+	for _, r := range encoded {
+		out, err := d.Feed(r)
+		if err != nil {
+			t.Fatalf("Feed after %d bytes: %v", len(got), err)
+		}
+		got = append(got, out...)
+	}
+	out, err := d.Finish()
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	got = append(got, out...)
+
+	if want, _ := Decode(encoded); !bytes.Equal(got, want) {
+		t.Errorf("incremental output differs from Decode")
+	}
+}
+
+func TestIncrementalDecoderError(t *testing.T) {
+	d := NewIncrementalDecoder()
+	for _, r := range "ABC" {
+		_, _ = d.Feed(r)
+	}
+	_, want := Decode("ABC")
+	if _, err := d.Finish(); err == nil || err.Error() != want.Error() {
+		t.Fatalf("Finish: got error %v, want %v", err, want)
+	}
+	if _, err := d.Feed(ThaiAlphabet[0]); err == nil {
+		t.Error("Feed after error: got nil, want sticky error")
+	}
+}
+
+func TestIncrementalEncoder(t *testing.T) {
+	input := make([]byte, 301)
+	_, _ = io.ReadFull(rand.Reader, input)
+
+	for _, enc := range []*Encoding{StdEncoding, StdEncoding.WithGroupSize(4).WithExplicitTerminator()} {
+		e := enc.NewIncrementalEncoder()
+		var got []rune
+		for _, b := range input {
+			got = append(got, e.Feed(b)...)
+		}
+		got = append(got, e.Finish()...)
+
+		if want := enc.Encode(input); string(got) != want {
+			t.Errorf("incremental output differs from Encode")
+		}
+	}
+
+	// Runes are released as soon as a pair completes
+	e := NewIncrementalEncoder()
+	if out := e.Feed('H'); len(out) != 0 {
+		t.Errorf("Feed of first byte returned %q, want nothing", string(out))
+	}
+	if got, want := string(e.Feed('i')), Encode([]byte("Hi")); got != want {
+		t.Errorf("Feed of second byte returned %q, want %q", got, want)
+	}
+}
+
+func TestDecodeToWriter(t *testing.T) {
+	input := make([]byte, 2001)
+	_, _ = io.ReadFull(rand.Reader, input)
+	encoded := Encode(input)
+
+	var buf bytes.Buffer
+	n, err := DecodeToWriter(&buf, encoded)
+	if err != nil {
+		t.Fatalf("DecodeToWriter: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), input) || n != int64(len(input)) {
+		t.Errorf("got %d bytes, want %d matching the input", n, len(input))
+	}
+
+	// Write error part-way through: decoding stops there
+	n, err = DecodeToWriter(&errWriter{limit: 100}, encoded)
+	if !errors.Is(err, errSink) || n != 100 {
+		t.Errorf("write error: got (%d, %v), want (100, %v)", n, err, errSink)
+	}
+
+	// Decode error after some output
+	buf.Reset()
+	if _, err := DecodeToWriter(&buf, encoded[:len(encoded)-3]); err == nil {
+		t.Error("expected error for truncated input, got nil")
+	}
+}
+
+func TestDecoderUnexpectedEOF(t *testing.T) {
+	for _, size := range []int{10, 11} {
+		runes := []rune(Encode(make([]byte, size)))
+
+		// Cut 1 or 2 runes into the last triplet
+		end := size / 2 * 3
+		for _, cut := range []int{end - 2, end - 1} {
+			_, err := io.ReadAll(NewDecoder(strings.NewReader(string(runes[:cut]))))
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("size %d, cut at %d: expected io.ErrUnexpectedEOF, got %v", size, cut, err)
+			}
+		}
+	}
+
+	// Corruption, rather than truncation, is not an unexpected EOF
+	for _, encoded := range []string{"ABC", string(BugineseAlphabet[0])} {
+		_, err := io.ReadAll(NewDecoder(strings.NewReader(encoded)))
+		if err == nil || errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%q: got %v, want a decode error", encoded, err)
+		}
+	}
+}
+
+func TestNewBytesReader(t *testing.T) {
+	input := []byte("Hello, World!")
+	r, err := NewBytesReader(wrapLines(Encode(input), 6))
+	if err != nil {
+		t.Fatalf("NewBytesReader: %v", err)
+	}
+	if err := iotest.TestReader(r, input); err != nil {
+		t.Error(err)
+	}
+
+	if r, err := NewBytesReader("ABC"); err == nil || r != nil {
+		t.Errorf("invalid input: got (%v, %v), want (nil, error)", r, err)
+	}
+}
+
+func TestDecoderPosition(t *testing.T) {
+	encoded := Encode(make([]byte, 1000))
+
+	// The first Read consumes one 1 KiB chunk: 341 whole runes, with the
+	// first byte of the next one held back
+	d := NewDecoder(strings.NewReader(encoded))
+	if _, err := d.Read(make([]byte, 10)); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if runes, bytes := d.Position(); runes != 341 || bytes != 1023 {
+		t.Errorf("after first read: Position = (%d, %d), want (341, 1023)", runes, bytes)
+	}
+
+	// An invalid character stops the decoder on it
+	bad := " " + encoded[:30] + "\n" + "x" + encoded[30:]
+	d = NewDecoder(strings.NewReader(bad))
+	if _, err := io.ReadAll(d); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if runes, bytes := d.Position(); runes != 12 || bytes != 32 {
+		t.Errorf("after error: Position = (%d, %d), want (12, 32)", runes, bytes)
+	}
+}
+
+func TestEncoderFlushThreshold(t *testing.T) {
+	input := []byte("Hello, World! Hello, padthai!") // 29 bytes
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf).WithFlushThreshold(30)
+
+	// 10 bytes are 15 runes: below the threshold, nothing is written
+	if _, err := enc.Write(input[:10]); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("below threshold: %d bytes written", buf.Len())
+	}
+
+	// 10 more reach 30 runes: everything so far appears before Close
+	if _, err := enc.Write(input[10:20]); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got, want := buf.String(), Encode(input[:20]); got != want {
+		t.Errorf("at threshold: got %q, want %q", got, want)
+	}
+
+	if _, err := enc.Write(input[20:]); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got, want := buf.String(), Encode(input); got != want {
+		t.Errorf("after close: got %q, want %q", got, want)
+	}
+
+	// Flush errors are sticky
+	enc = NewEncoder(&errWriter{}).WithFlushThreshold(3)
+	if _, err := enc.Write(input[:2]); !errors.Is(err, errSink) {
+		t.Errorf("write: got %v, want %v", err, errSink)
+	}
+	if err := enc.Close(); !errors.Is(err, errSink) {
+		t.Errorf("close: got %v, want %v", err, errSink)
+	}
+}
+
+func TestDecodeRuneReader(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 101, 1000} {
+		input := make([]byte, size)
+		_, _ = io.ReadFull(rand.Reader, input)
+		encoded := wrapLines(Encode(input), 7)
+
+		decoded, err := DecodeRuneReader(bufio.NewReader(strings.NewReader(encoded)))
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Errorf("size %d: roundtrip mismatch", size)
+		}
+	}
+
+	runes := []rune(Encode(make([]byte, 10)))
+	_, err := DecodeRuneReader(bufio.NewReader(strings.NewReader(string(runes[:13]))))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated input: expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	_, err = DecodeRuneReader(bufio.NewReader(strings.NewReader(string(runes[:3]) + "\xff")))
+	if !errors.Is(err, ErrInvalidUTF8) {
+		t.Errorf("invalid UTF-8: expected ErrInvalidUTF8, got %v", err)
+	}
+}
+
+func TestEncoderStats(t *testing.T) {
+	for _, threshold := range []int{0, 10} {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf).WithFlushThreshold(threshold)
+		for _, chunk := range []string{"Hel", "lo, ", "World!"} {
+			if _, err := enc.WriteString(chunk); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+		}
+		if got := enc.Stats().BytesIn; got != 13 {
+			t.Errorf("threshold %d: %d bytes in before Close, want 13", threshold, got)
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("close: %v", err)
+		}
+
+		want := EncoderStats{
+			BytesIn:  13,
+			RunesOut: int64(EncodedRuneLen(13)),
+			BytesOut: int64(buf.Len()),
+		}
+		if got := enc.Stats(); got != want {
+			t.Errorf("threshold %d: Stats() = %+v, want %+v", threshold, got, want)
+		}
+	}
+}
+
+// spacedReader yields the encoding of reps byte pairs, each followed by a
+// run of gap spaces, without holding the text in memory.
+type spacedReader struct {
+	reps, gap int
+	digits    []byte // pending digits
+	spaces    int    // pending spaces
+}
+
+var spaces = bytes.Repeat([]byte(" "), 1024)
+
+func (r *spacedReader) Read(p []byte) (int, error) {
+	if len(r.digits) == 0 && r.spaces == 0 {
+		if r.reps == 0 {
+			return 0, io.EOF
+		}
+		r.reps--
+		r.digits = []byte(Encode([]byte{byte(r.reps), byte(r.reps >> 8)}))
+		r.spaces = r.gap
+	}
+	if len(r.digits) > 0 {
+		n := copy(p, r.digits)
+		r.digits = r.digits[n:]
+		return n, nil
+	}
+	n := copy(p, spaces[:min(r.spaces, len(spaces))])
+	r.spaces -= n
+	return n, nil
+}
+
+func TestDecoderMemoryFlat(t *testing.T) {
+	// Decoding allocates per chunk of input, but how much it buffers must
+	// not depend on the length of the whitespace runs
+	allocs := func(gap int) uint64 {
+		src := &spacedReader{reps: 64, gap: gap}
+		dec := NewDecoder(src)
+		buf := make([]byte, 64)
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		total := 0
+		for {
+			n, err := dec.Read(buf)
+			total += n
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+		}
+		runtime.ReadMemStats(&after)
+		if total != 128 {
+			t.Fatalf("decoded %d bytes, want 128", total)
+		}
+		return after.TotalAlloc - before.TotalAlloc
+	}
+
+	// 64 runs of 32 KiB of spaces: 2 MiB of whitespace
+	small, large := allocs(1), allocs(32<<10)
+	t.Logf("allocated %d bytes with short gaps, %d with 32 KiB gaps", small, large)
+	if large > small+64<<10 {
+		t.Errorf("decoding 2 MiB of whitespace allocated %d bytes, against %d without", large, small)
+	}
+}
+
+func TestEncoderCloseEdges(t *testing.T) {
+	input := []byte("Hello, World!!")
+	tests := []struct {
+		name   string
+		writes [][]byte
+	}{
+		{"single odd byte", [][]byte{{0x42}}},
+		{"nothing", nil},
+		{"empty write", [][]byte{{}}},
+		{"odd byte last", [][]byte{input[:12], input[12:13]}},
+		{"odd byte then empty", [][]byte{input[:13], {}}},
+		{"even then empty", [][]byte{input, {}}},
+		{"empty between halves", [][]byte{input[:1], {}, input[1:3]}},
+	}
+
+	// Many 1-byte writes, for both parities
+	for _, n := range []int{13, 14} {
+		var writes [][]byte
+		for i := range n {
+			writes = append(writes, input[i:i+1])
+		}
+		tests = append(tests, struct {
+			name   string
+			writes [][]byte
+		}{fmt.Sprintf("%d 1-byte writes", n), writes})
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		var total []byte
+		for _, w := range tt.writes {
+			if n, err := enc.Write(w); n != len(w) || err != nil {
+				t.Fatalf("%s: Write = %d, %v", tt.name, n, err)
+			}
+			total = append(total, w...)
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("%s: Close: %v", tt.name, err)
+		}
+		if got, want := buf.String(), Encode(total); got != want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, want)
+		}
+		pad := 0
+		for _, r := range buf.String() {
+			if isBuginese(r) {
+				pad++
+			}
+		}
+		if want := 2 * (len(total) % 2); pad != want {
+			t.Errorf("%s: %d padding runes for %d bytes, want %d", tt.name, pad, len(total), want)
+		}
+
+		// Closing again writes nothing more
+		if err := enc.Close(); err != nil || buf.String() != Encode(total) {
+			t.Errorf("%s: second Close wrote %q, %v", tt.name, buf.String(), err)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package padthai
+
+import "fmt"
+
+// Transcode decodes s with from and re-encodes the result with to, for moving
+// data between alphabets without handling the bytes in between. Decoding
+// errors are wrapped to say which step failed. Blank input, which decodes to
+// no bytes, transcodes to the encoding of no bytes under to.
+func Transcode(s string, from, to *Encoding) (string, error) {
+	data, err := from.Decode(s)
+	if err != nil {
+		return "", fmt.Errorf("padthai: transcode: %w", err)
+	}
+	return to.Encode(data), nil
+}
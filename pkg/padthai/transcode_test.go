@@ -0,0 +1,45 @@
+package padthai
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTranscode(t *testing.T) {
+	main, pad := latinAlphabets()
+	latin, err := NewEncoding(main, pad)
+	if err != nil {
+		t.Fatalf("NewEncoding: %v", err)
+	}
+
+	input := []byte("Hello, World!")
+	encoded := Encode(input)
+
+	there, err := Transcode(encoded, StdEncoding, latin)
+	if err != nil {
+		t.Fatalf("to latin: %v", err)
+	}
+	if want := latin.Encode(input); there != want {
+		t.Errorf("to latin: got %q, want %q", there, want)
+	}
+
+	back, err := Transcode(there, latin, StdEncoding)
+	if err != nil {
+		t.Fatalf("back: %v", err)
+	}
+	if back != encoded {
+		t.Errorf("back: got %q, want %q", back, encoded)
+	}
+
+	if got, err := Transcode(" \n", StdEncoding, StdEncoding.WithExplicitTerminator()); err != nil || got != bugineseTerminator {
+		t.Errorf("blank input: got (%q, %v), want the bare terminator", got, err)
+	}
+
+	_, err = Transcode(encoded, latin, StdEncoding)
+	if err == nil {
+		t.Fatal("expected error decoding with the wrong encoding, got nil")
+	}
+	if _, want := latin.Decode(encoded); errors.Unwrap(err).Error() != want.Error() {
+		t.Errorf("error %v does not wrap %v", err, want)
+	}
+}
@@ -0,0 +1,41 @@
+package padthai
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// DecodeURLEscaped decodes padthai text that has been percent-escaped, as
+// happens when it travels in a URL query string (ก becomes %E0%B8%81). The
+// input is unescaped with url.QueryUnescape, so a '+' becomes a space and is
+// skipped like any other whitespace.
+func DecodeURLEscaped(s string) ([]byte, error) {
+	unescaped, err := url.QueryUnescape(s)
+	if err != nil {
+		i := badEscape(s)
+		prefix, _ := url.QueryUnescape(s[:i])
+		return nil, &DecodeError{Kind: InvalidChar, RuneOffset: keptRuneCount(prefix), ByteOffset: i, Rune: '%',
+			Err: fmt.Errorf("%w: invalid percent-escape: %w", ErrInvalidCharacter, err)}
+	}
+	data, err := Decode(unescaped)
+	if err != nil {
+		// Offsets in the unescaped text do not map back to s
+		return nil, withByteOffset(err, func(int) int { return -1 })
+	}
+	return data, nil
+}
+
+// badEscape returns the offset of the first '%' in s not followed by two
+// hex digits, or len(s) if there is none.
+func badEscape(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && (i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2])) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,28 @@
+package padthai
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDecodeURLEscaped(t *testing.T) {
+	input := []byte("query=padthai&odd")
+	escaped := url.QueryEscape(Encode(input))
+	if !strings.HasPrefix(escaped, "%E0%B8") {
+		t.Fatalf("expected a percent-escaped Thai rune, got %q", escaped[:9])
+	}
+
+	decoded, err := DecodeURLEscaped(escaped)
+	if err != nil {
+		t.Fatalf("DecodeURLEscaped: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("got %q, want %q", decoded, input)
+	}
+
+	if _, err := DecodeURLEscaped("%E0%B8%8"); err == nil {
+		t.Error("expected error for malformed escape, got nil")
+	}
+}
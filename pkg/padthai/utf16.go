@@ -0,0 +1,48 @@
+package padthai
+
+import (
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// EncodeToUTF16 encodes data like Encode but returns the UTF-16 code units of
+// the result, for consumers such as JVM or .NET strings.
+//
+// Every Thai and Buginese rune is in the Basic Multilingual Plane, so each
+// rune maps to exactly one code unit.
+func EncodeToUTF16(data []byte) []uint16 {
+	encoded := Encode(data)
+	out := make([]uint16, 0, len(encoded)/3)
+	for _, r := range encoded {
+		out = append(out, uint16(r))
+	}
+	return out
+}
+
+// DecodeUTF16 decodes padthai text given as UTF-16 code units. It is the
+// inverse of EncodeToUTF16.
+//
+// Since no encoding rune needs a surrogate pair, any surrogate code unit is
+// rejected as invalid input. The ByteOffset of a DecodeError counts code
+// units.
+func DecodeUTF16(units []uint16) ([]byte, error) {
+	kept := 0
+	for i, u := range units {
+		if utf16.IsSurrogate(rune(u)) {
+			return nil, &DecodeError{Kind: InvalidChar, RuneOffset: kept, ByteOffset: i, Rune: rune(u),
+				Err: fmt.Errorf("%w: unexpected UTF-16 surrogate %#04x at position %d", ErrInvalidCharacter, u, i)}
+		}
+		if !isSpace(rune(u)) {
+			kept++
+		}
+	}
+	s := string(utf16.Decode(units))
+	data, err := Decode(s)
+	if err != nil {
+		// Every rune is a single code unit, so offsets in units are offsets
+		// in runes of s
+		return nil, withByteOffset(err, func(off int) int { return utf8.RuneCountInString(s[:off]) })
+	}
+	return data, nil
+}
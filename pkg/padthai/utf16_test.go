@@ -0,0 +1,37 @@
+package padthai
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+func TestUTF16RoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 3, 100, 257} {
+		input := make([]byte, size)
+		_, _ = io.ReadFull(rand.Reader, input)
+
+		units := EncodeToUTF16(input)
+		if want := len([]rune(Encode(input))); len(units) != want {
+			t.Errorf("size %d: expected %d code units, got %d", size, want, len(units))
+		}
+
+		decoded, err := DecodeUTF16(units)
+		if err != nil {
+			t.Fatalf("size %d: decode: %v", size, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Errorf("size %d: roundtrip mismatch", size)
+		}
+	}
+}
+
+func TestDecodeUTF16Surrogate(t *testing.T) {
+	units := EncodeToUTF16([]byte{0x12, 0x34})
+	units = append(units, 0xD83D, 0xDE00) // a valid pair, but not padthai
+
+	if _, err := DecodeUTF16(units); err == nil {
+		t.Error("expected error for surrogate code units, got nil")
+	}
+}
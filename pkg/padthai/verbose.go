@@ -0,0 +1,115 @@
+package padthai
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DecodeVerbose is like Decode but also returns warnings about input that
+// decodes successfully yet is not what Encode would have produced, such as
+// wrapped or trailing whitespace. Tools can use them to nudge users towards
+// clean encodings. On a hard error, data and warnings are both nil.
+//
+// Warnings are meant for humans; their wording may change.
+func DecodeVerbose(s string) (data []byte, warnings []string, err error) {
+	data, err = Decode(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	spaces := 0
+	for _, r := range s {
+		if isSpace(r) {
+			spaces++
+		}
+	}
+	if spaces > 0 {
+		warnings = append(warnings, fmt.Sprintf("contained %d whitespace characters", spaces))
+	}
+	if strings.TrimRightFunc(s, unicode.IsSpace) != s {
+		warnings = append(warnings, "input has trailing whitespace")
+	}
+	if Encode(data) != s {
+		warnings = append(warnings, "input was not canonical")
+	}
+	return data, warnings, nil
+}
+
+// DecodeCanonical decodes s using StdEncoding and also returns its canonical
+// form, the string Encode returns for the decoded data, for storage layers
+// that keep only canonical encodings. If s is not already canonical, as when
+// it is wrapped or padded with whitespace, DecodeCanonical returns an error
+// wrapping ErrNonCanonical, unless normalize is set: then input that decodes
+// is accepted, whatever runes it skips, and canonical is its normal form. On
+// error, data and canonical are both empty.
+func DecodeCanonical(s string, normalize bool) (data []byte, canonical string, err error) {
+	return StdEncoding.DecodeCanonical(s, normalize)
+}
+
+// DecodeCanonical is like the package-level DecodeCanonical but uses enc.
+// What input decodes is up to enc: a lenient enc, for instance, also accepts
+// the runes it strips or aliases, but reports them as not canonical unless
+// normalize is set.
+func (enc *Encoding) DecodeCanonical(s string, normalize bool) (data []byte, canonical string, err error) {
+	data, err = enc.Decode(s)
+	if err != nil {
+		return nil, "", err
+	}
+	canonical = enc.Encode(data)
+	if canonical != s && !normalize {
+		i := 0
+		for i < len(s) && i < len(canonical) && s[i] == canonical[i] {
+			i++
+		}
+		return nil, "", &DecodeError{Kind: NotCanonical, RuneOffset: utf8.RuneCountInString(canonical[:i]), ByteOffset: i, Rune: -1,
+			Err: fmt.Errorf("%w: input differs from its canonical encoding at byte offset %d", ErrNonCanonical, i)}
+	}
+	return data, canonical, nil
+}
+
+// ocrConfusables maps the Arabic digits OCR software tends to read in place
+// of Thai digits to the letters they stand for.
+var ocrConfusables = map[rune]rune{
+	'0': 'อ',
+	'3': 'ว',
+	'5': 'ร',
+	'9': 'จ',
+}
+
+// DecodeOCR is like Decode but first repairs the Thai letters that OCR
+// software commonly misreads as Arabic digits in scanned padthai: 0 as อ,
+// 3 as ว, 5 as ร and 9 as จ. Each repair is reported as a warning, with its
+// position counted in runes, whitespace excluded, as in Decode errors. On a
+// hard error, data and warnings are both nil.
+//
+// This is a best-effort aid: a repaired input that decodes is not
+// necessarily what was scanned, so callers should use a checksum, such as
+// EncodeWithCheckDigit provides, where it matters. Warnings are meant for
+// humans; their wording may change.
+func DecodeOCR(s string) (data []byte, warnings []string, err error) {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	pos := 0
+	for _, r := range s {
+		if isSpace(r) {
+			sb.WriteRune(r)
+			continue
+		}
+		if fix, ok := ocrConfusables[r]; ok {
+			warnings = append(warnings, fmt.Sprintf("read %c at position %d as %c", r, pos, fix))
+			r = fix
+		}
+		sb.WriteRune(r)
+		pos++
+	}
+
+	data, err = Decode(sb.String())
+	if err != nil {
+		// Repairs change the length of s, so the offset in the repaired text
+		// is not one in s
+		return nil, nil, withByteOffset(err, func(int) int { return -1 })
+	}
+	return data, warnings, nil
+}
@@ -0,0 +1,106 @@
+package padthai
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDecodeVerbose(t *testing.T) {
+	input := []byte("Hello, World!")
+	encoded := Encode(input)
+
+	data, warnings, err := DecodeVerbose(encoded)
+	if err != nil || !bytes.Equal(data, input) || len(warnings) != 0 {
+		t.Errorf("canonical: got (%q, %q, %v), want (%q, none, nil)", data, warnings, err, input)
+	}
+
+	data, warnings, err = DecodeVerbose(wrapLines(encoded, 6) + "\n")
+	if err != nil {
+		t.Fatalf("wrapped: %v", err)
+	}
+	if !bytes.Equal(data, input) {
+		t.Errorf("wrapped: got %q, want %q", data, input)
+	}
+	want := []string{"contained 4 whitespace characters", "input has trailing whitespace", "input was not canonical"}
+	if !slices.Equal(warnings, want) {
+		t.Errorf("wrapped: warnings = %q, want %q", warnings, want)
+	}
+
+	data, warnings, err = DecodeVerbose("ABC")
+	if err == nil || data != nil || warnings != nil {
+		t.Errorf("invalid: got (%q, %q, %v), want (nil, nil, error)", data, warnings, err)
+	}
+}
+
+func TestDecodeCanonical(t *testing.T) {
+	input := []byte("Hello, World!")
+	encoded := Encode(input)
+
+	for _, normalize := range []bool{false, true} {
+		data, canonical, err := DecodeCanonical(encoded, normalize)
+		if err != nil || !bytes.Equal(data, input) || canonical != encoded {
+			t.Errorf("normalize %v, canonical input: got %q, %q, %v", normalize, data, canonical, err)
+		}
+	}
+
+	// Whitespace is rejected, or normalized on request
+	padded := " " + encoded[:9] + "\n" + encoded[9:] + "\n"
+	_, _, err := DecodeCanonical(padded, false)
+	var de *DecodeError
+	if !errors.Is(err, ErrNonCanonical) || !errors.As(err, &de) || de.Kind != NotCanonical {
+		t.Errorf("padded input: got %v, want ErrNonCanonical of kind NotCanonical", err)
+	}
+	// Corrupt digits are told apart by their kind
+	if _, _, err := DecodeCanonical("กกก ฯฯฯ", true); !errors.As(err, &de) || de.Kind != OutOfRange {
+		t.Errorf("non-canonical digits: got %v, want kind OutOfRange", err)
+	}
+	data, canonical, err := DecodeCanonical(padded, true)
+	if err != nil || !bytes.Equal(data, input) || canonical != encoded {
+		t.Errorf("normalized, padded input: got %q, %q, %v", data, canonical, err)
+	}
+
+	// Leniency only widens what decodes: stripped runes are still reported
+	selected := encoded[:9] + "\ufe0f" + encoded[9:]
+	if _, _, err := StdEncoding.Lenient().DecodeCanonical(selected, false); !errors.Is(err, ErrNonCanonical) {
+		t.Errorf("lenient, variation selector: got %v, want ErrNonCanonical", err)
+	}
+	if _, canonical, err := StdEncoding.Lenient().DecodeCanonical(selected, true); err != nil || canonical != encoded {
+		t.Errorf("lenient, normalized variation selector: got %q, %v", canonical, err)
+	}
+
+	for _, normalize := range []bool{false, true} {
+		if data, canonical, err := DecodeCanonical(encoded[:len(encoded)-3], normalize); err == nil || data != nil || canonical != "" {
+			t.Errorf("normalize %v, invalid input: got %q, %q, %v", normalize, data, canonical, err)
+		}
+	}
+}
+
+func TestDecodeOCR(t *testing.T) {
+	// 0x068C is 1676 = 0*48² + 34*48 + 44, digits กรอ
+	input := []byte{0x06, 0x8C, 0x21}
+	if got, want := Encode(input), "กรอᨂᨁ"; got != want {
+		t.Fatalf("Encode = %q, want %q", got, want)
+	}
+	scanned := "ก5\n0ᨂᨁ"
+	if _, err := Decode(scanned); err == nil {
+		t.Fatalf("misread input decoded without repair")
+	}
+
+	data, warnings, err := DecodeOCR(scanned)
+	if err != nil {
+		t.Fatalf("DecodeOCR: %v", err)
+	}
+	if !bytes.Equal(data, input) {
+		t.Errorf("got %x, want %x", data, input)
+	}
+	want := []string{"read 5 at position 1 as ร", "read 0 at position 2 as อ"}
+	if !slices.Equal(warnings, want) {
+		t.Errorf("warnings = %q, want %q", warnings, want)
+	}
+
+	if data, warnings, err := DecodeOCR("12"); err == nil || data != nil || warnings != nil {
+		t.Errorf("invalid input: got %q, %q, %v", data, warnings, err)
+	}
+}